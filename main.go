@@ -16,8 +16,11 @@ import (
 	"golang.org/x/term"
 
 	"github.com/HuBeZa/synth/models"
+	"github.com/HuBeZa/synth/models/granular"
 	"github.com/HuBeZa/synth/models/keyboard"
+	"github.com/HuBeZa/synth/models/kitty"
 	"github.com/HuBeZa/synth/models/oscillator"
+	"github.com/HuBeZa/synth/models/ringmod"
 )
 
 const defaultSampleRate = beep.SampleRate(48000)
@@ -29,10 +32,12 @@ var (
 
 type mainModel struct {
 	streamers []tea.Model
+	// true if the terminal reports key release events
+	hasKeyRelease bool
 }
 
 func newModel() tea.Model {
-	m := mainModel{}.addNewKeyboard()
+	m, _ := mainModel{}.addNewKeyboard()
 	return m
 }
 
@@ -43,18 +48,33 @@ func (m mainModel) Init() tea.Cmd {
 func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if i := m.focusedStreamer(); i != -1 && msg.String() != "ctrl+c" {
+			var cmd tea.Cmd
+			m.streamers[i], cmd = m.streamers[i].Update(msg)
+			return m, cmd
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "ctrl+q":
 			speaker.Close()
 			return m, tea.Quit
 		case "ctrl+k":
-			return m.addNewKeyboard(), nil
+			return m.addNewKeyboard()
+		case "ctrl+s":
+			return m.addNewSampler()
 		case "ctrl+o":
-			return m.addNewOscillator(), nil
+			return m.addNewOscillator()
+		case "ctrl+r":
+			return m.addNewRingModulator()
+		case "ctrl+g":
+			return m.addNewGranular()
 		default:
 			return m.updateStreamers(msg)
 		}
-	case timer.TickMsg:
+	case timer.TickMsg, models.KeyReleaseMsg:
+		return m.updateStreamers(msg)
+	case models.KeyReleaseSupportedMsg:
+		m.hasKeyRelease = true
 		return m.updateStreamers(msg)
 	case models.StreamerUpMsg:
 		return m.moveStreamer(msg.Model, -1)
@@ -62,6 +82,12 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.moveStreamer(msg.Model, +1)
 	case models.RemoveStreamerMsg:
 		return m.removeStreamer(msg.Model)
+	case models.RackChangedMsg:
+		return m.updateStreamers(msg)
+	case models.RoutingChangedMsg:
+		m.restartSpeaker()
+		// the other routing models refresh their inputs, as the changed routing model may have claimed some
+		return m, m.rackChanged()
 	case tea.MouseMsg:
 		for i := range m.streamers {
 			if zone.Get(getStreamerZoneId(i)).InBounds(msg) {
@@ -86,6 +112,16 @@ func (m mainModel) updateStreamers(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// focusedStreamer returns the index of the streamer with a focused text input, or -1 if there is none
+func (m mainModel) focusedStreamer() int {
+	for i, streamer := range m.streamers {
+		if focusable, ok := streamer.(models.FocusableModel); ok && focusable.Focused() {
+			return i
+		}
+	}
+	return -1
+}
+
 func (m mainModel) View() string {
 	return zone.Scan(
 		lipgloss.JoinVertical(lipgloss.Left, m.renderStreamers(), m.renderHelp()),
@@ -131,27 +167,44 @@ func (m mainModel) renderStreamers() string {
 }
 
 func (m mainModel) renderHelp() string {
-	return helpStyle.Render("ctrl+k: add keyboard • ctrl+o: add oscillator • ctrl-q: exit")
+	return helpStyle.Render("ctrl+k: add keyboard • ctrl+s: add sampler • ctrl+o: add oscillator • ctrl+r: add ring modulator • ctrl+g: add granular • space: sustain • tab: latch • ctrl-q: exit")
 }
 
-func (m mainModel) addNewKeyboard() mainModel {
-	model := keyboard.New(defaultSampleRate)
-	speaker.Play(model.Streamer())
-	m.streamers = append(m.streamers, model)
-	return m
+func (m mainModel) addNewKeyboard() (mainModel, tea.Cmd) {
+	return m.addStreamer(keyboard.New(defaultSampleRate))
+}
+
+func (m mainModel) addNewSampler() (mainModel, tea.Cmd) {
+	return m.addStreamer(keyboard.NewSampler(defaultSampleRate))
+}
+
+func (m mainModel) addNewOscillator() (mainModel, tea.Cmd) {
+	return m.addStreamer(oscillator.New(defaultSampleRate))
+}
+
+func (m mainModel) addNewRingModulator() (mainModel, tea.Cmd) {
+	return m.addStreamer(ringmod.New(defaultSampleRate))
+}
+
+func (m mainModel) addNewGranular() (mainModel, tea.Cmd) {
+	return m.addStreamer(granular.New(defaultSampleRate))
 }
 
-func (m mainModel) addNewOscillator() mainModel {
-	model := oscillator.New(defaultSampleRate)
+func (m mainModel) addStreamer(model models.StreamerModel) (mainModel, tea.Cmd) {
+	if m.hasKeyRelease {
+		updated, _ := model.Update(models.KeyReleaseSupportedMsg{})
+		model = updated.(models.StreamerModel)
+	}
 	speaker.Play(model.Streamer())
 	m.streamers = append(m.streamers, model)
-	return m
+	return m, m.rackChanged()
 }
 
 func (m mainModel) moveStreamer(model models.StreamerModel, diff int) (tea.Model, tea.Cmd) {
 	i := m.indexOf(model)
 	if i != -1 && i+diff >= 0 && i+diff < len(m.streamers) {
 		m.streamers[i], m.streamers[i+diff] = m.streamers[i+diff], m.streamers[i]
+		return m, m.rackChanged()
 	}
 	return m, nil
 }
@@ -160,10 +213,19 @@ func (m mainModel) removeStreamer(model models.StreamerModel) (tea.Model, tea.Cm
 	if i := m.indexOf(model); i != -1 {
 		m.streamers = slices.Delete(m.streamers, i, i+1)
 		m.restartSpeaker()
+		return m, m.rackChanged()
 	}
 	return m, nil
 }
 
+func (m mainModel) rackChanged() tea.Cmd {
+	streamers := make([]models.StreamerModel, len(m.streamers))
+	for i, streamer := range m.streamers {
+		streamers[i] = streamer.(models.StreamerModel)
+	}
+	return models.RackChangedFunc(streamers)
+}
+
 func (m mainModel) indexOf(model models.StreamerModel) int {
 	for i, streamerModel := range m.streamers {
 		if model.Equals(streamerModel) {
@@ -173,15 +235,31 @@ func (m mainModel) indexOf(model models.StreamerModel) int {
 	return -1
 }
 
+// restartSpeaker plays all streamers, except for those routed into another streamer
 func (m mainModel) restartSpeaker() {
-	streamers := make([]beep.Streamer, len(m.streamers))
-	for i, streamer := range m.streamers {
-		streamers[i] = streamer.(models.StreamerModel).Streamer()
+	streamers := make([]beep.Streamer, 0, len(m.streamers))
+	for _, streamer := range m.streamers {
+		if !m.isRouted(streamer) {
+			streamers = append(streamers, streamer.(models.StreamerModel).Streamer())
+		}
 	}
 	speaker.Clear()
 	speaker.Play(streamers...)
 }
 
+func (m mainModel) isRouted(model tea.Model) bool {
+	for _, streamer := range m.streamers {
+		if routingModel, ok := streamer.(models.RoutingModel); ok {
+			for _, input := range routingModel.Inputs() {
+				if input.Equals(model) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 func getStreamerZoneId(i int) string {
 	return fmt.Sprintf("streamer_%v", i)
 }
@@ -196,7 +274,9 @@ func main() {
 	speaker.Init(defaultSampleRate, defaultSampleRate.N(time.Second/10))
 
 	m := newModel()
-	_, err := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion()).Run()
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithInput(kitty.Input()), tea.WithOutput(kitty.Output()))
+	kitty.SetProgram(p)
+	_, err := p.Run()
 	if err != nil {
 		fmt.Println("Error running program:", err)
 		os.Exit(1)
@@ -2,6 +2,9 @@ package chords
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -9,48 +12,125 @@ import (
 	zone "github.com/lrstanley/bubblezone"
 
 	"github.com/HuBeZa/synth/models"
+	"github.com/HuBeZa/synth/models/base/checkbox"
 	"github.com/HuBeZa/synth/models/base/options"
+	"github.com/HuBeZa/synth/models/base/pathinput"
 	"github.com/HuBeZa/synth/models/base/slider"
+	"github.com/HuBeZa/synth/models/base/spinner"
 	"github.com/HuBeZa/synth/streamers/chords"
+	"github.com/HuBeZa/synth/streamers/scales"
 )
 
 const (
+	fileInputWidth = 24
+
 	// bubblezone ids:
-	chordsOptionsId = "chordsOptions"
-	delaySliderId   = "delaySlider"
+	chordsOptionsId    = "chordsOptions"
+	modeOptionsId      = "modeOptions"
+	sizeOptionsId      = "sizeOptions"
+	inversionSpinnerId = "inversionSpinner"
+	voicingOptionsId   = "voicingOptions"
+	doublingCheckboxId = "doublingCheckbox"
+	delaySliderId      = "delaySlider"
+	fileInputId        = "fileInput"
 )
 
 var (
 	delayValues = []time.Duration{0, 50 * time.Millisecond, 100 * time.Millisecond, 150 * time.Millisecond, 200 * time.Millisecond, 250 * time.Millisecond, 
 		300 * time.Millisecond, 350 * time.Millisecond, 400 * time.Millisecond, 450 * time.Millisecond, 500 * time.Millisecond}
+	errorStyle = models.ForegroundColor("#DF0000")
+)
+
+type Mode int
+
+const (
+	// Fixed plays the same chord type on every root
+	Fixed Mode = iota
+	// Diatonic plays the chord of the scale degree of each root, within the selected key
+	Diatonic
 )
 
+func Modes() []Mode {
+	return []Mode{Fixed, Diatonic}
+}
+
+func (m Mode) Equals(other Mode) bool {
+	return m == other
+}
+
+func (m Mode) String() string {
+	switch m {
+	case Fixed:
+		return "fixed"
+	case Diatonic:
+		return "diatonic"
+	default:
+		return strconv.Itoa(int(m))
+	}
+}
+
 type Model interface {
 	tea.Model
+	// Chord returns the selected chord type in fixed mode, or nil
 	Chord() chords.ChordType
+	// IsDiatonic returns true if a chord size is selected in diatonic mode
+	IsDiatonic() bool
+	// DiatonicChord returns the chord of the scale degree of root (semitones above C) in diatonic mode,
+	// or nil if root is out of the key or the key is not heptatonic
+	DiatonicChord(key scales.Key, root int) chords.ChordType
 	ArpeggioDelay() time.Duration
+	// ChordTypes returns the built-in chords, followed by the custom chords loaded from file
+	ChordTypes() []chords.ChordType
+	// Focused returns true while the custom chords file path is edited
+	Focused() bool
 }
 
 type model struct {
-	chordsOptions options.Model[chords.ChordType]
-	delaySlider   slider.Model
-	zonePrefix    string
-	zoneHandlers  models.ZoneHandlers[model]
+	modeOptions      options.Model[Mode]
+	chordsOptions    options.Model[chords.ChordType]
+	chordTypes       []chords.ChordType
+	sizeOptions      options.Model[chords.DiatonicSize]
+	inversionSpinner spinner.Model[string]
+	voicingOptions   options.Model[chords.Voicing]
+	doublingCheckbox checkbox.Model
+	delaySlider      slider.Model
+	fileInput        pathinput.Model
+	loadErr          error
+	zonePrefix       string
+	zoneHandlers     models.ZoneHandlers[model]
 }
 
 func New() Model {
 	m := model{}
-	m.chordsOptions = options.New(chords.ChordTypes(), true)
+	m.modeOptions = options.New(Modes(), false)
+	m.setCustomChords(nil)
+	m.sizeOptions = options.New(chords.DiatonicSizes(), true)
+	m.inversionSpinner = spinner.New(chords.InversionNames(), false)
+	m.voicingOptions = options.New(chords.Voicings(), false)
+	m.doublingCheckbox = checkbox.New("8va", false)
 	m.delaySlider, _ = slider.New(0, len(delayValues)-1, 1, 0, len(delayValues)/2)
+	m.fileInput = pathinput.New("click to load chords file", fileInputWidth)
 	m.zonePrefix = zone.NewPrefix()
 	m.zoneHandlers = models.ZoneHandlers[model]{
-		m.zonePrefix + chordsOptionsId: chordsOptionsHandler,
-		m.zonePrefix + delaySliderId:   delaySliderHandler,
+		m.zonePrefix + modeOptionsId:      modeOptionsHandler,
+		m.zonePrefix + chordsOptionsId:    chordsOptionsHandler,
+		m.zonePrefix + sizeOptionsId:      sizeOptionsHandler,
+		m.zonePrefix + inversionSpinnerId: inversionSpinnerHandler,
+		m.zonePrefix + voicingOptionsId:   voicingOptionsHandler,
+		m.zonePrefix + doublingCheckboxId: doublingCheckboxHandler,
+		m.zonePrefix + delaySliderId:      delaySliderHandler,
+		m.zonePrefix + fileInputId:        fileInputHandler,
 	}
 
 	return m
 }
 
+// setCustomChords sets the chord types & their options to the built-in chords, followed by the custom chords
+func (m *model) setCustomChords(custom []chords.ChordType) {
+	m.chordTypes = append(chords.ChordTypes(), custom...)
+	m.chordsOptions = options.New(m.chordTypes, true).SetWidth(models.ColumnWidth - models.LabelStyle().GetWidth())
+}
+
 func (m model) Init() tea.Cmd {
 	return nil
 }
@@ -59,16 +139,86 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.MouseMsg:
 		return m.zoneHandlers.Handle(m, msg)
+	case tea.KeyMsg:
+		return m.updateFileInput(msg)
 	}
 	return m, nil
 }
 
+func modeOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	optionsModel, cmd := m.modeOptions.Update(msg)
+	m.modeOptions = optionsModel.(options.Model[Mode])
+	return m, cmd
+}
+
+func sizeOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	optionsModel, cmd := m.sizeOptions.Update(msg)
+	m.sizeOptions = optionsModel.(options.Model[chords.DiatonicSize])
+	return m, cmd
+}
+
 func chordsOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	optionsModel, cmd := m.chordsOptions.Update(msg)
 	m.chordsOptions = optionsModel.(options.Model[chords.ChordType])
 	return m, cmd
 }
 
+func inversionSpinnerHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	spinnerModel, cmd := m.inversionSpinner.Update(msg)
+	m.inversionSpinner = spinnerModel.(spinner.Model[string])
+	return m, cmd
+}
+
+func voicingOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	optionsModel, cmd := m.voicingOptions.Update(msg)
+	m.voicingOptions = optionsModel.(options.Model[chords.Voicing])
+	return m, cmd
+}
+
+func doublingCheckboxHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	checkboxModel, cmd := m.doublingCheckbox.Update(msg)
+	m.doublingCheckbox = checkboxModel.(checkbox.Model)
+	return m, cmd
+}
+
+func fileInputHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	return m.updateFileInput(msg)
+}
+
+func (m model) updateFileInput(msg tea.Msg) (tea.Model, tea.Cmd) {
+	inputModel, cmd := m.fileInput.Update(msg)
+	m.fileInput = inputModel.(pathinput.Model)
+	if _, ok := msg.(tea.KeyMsg); ok && !m.fileInput.Focused() {
+		m.loadChords()
+	}
+	return m, cmd
+}
+
+// loadChords adds the chords of the submitted file to the options. An empty path removes the custom chords.
+func (m *model) loadChords() {
+	path := strings.TrimSpace(m.fileInput.Value())
+	selected := m.chordsOptions.Value()
+	if path == "" {
+		m.loadErr = nil
+		m.setCustomChords(nil)
+		if selected != nil {
+			m.chordsOptions = m.chordsOptions.SetValue(selected)
+		}
+		return
+	}
+
+	custom, err := chords.LoadChordTypes(path)
+	if err != nil {
+		m.loadErr = err
+		return
+	}
+	m.loadErr = nil
+	m.setCustomChords(custom)
+	if selected != nil {
+		m.chordsOptions = m.chordsOptions.SetValue(selected)
+	}
+}
+
 func delaySliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	sliderModel, cmd := m.delaySlider.Update(msg)
 	m.delaySlider = sliderModel.(slider.Model)
@@ -79,23 +229,51 @@ func (m model) View() string {
 	return lipgloss.JoinHorizontal(lipgloss.Top,
 		m.renderLabel(),
 		lipgloss.JoinVertical(lipgloss.Left,
+			m.renderModeOptions(),
 			m.renderChordsOptions(),
-			m.renderDelaySlider()))
+			m.renderVoicing(),
+			m.renderDelaySlider(),
+			m.renderFileInput()))
 }
 
 func (m model) renderLabel() string {
 	label := models.LabelStyle().Render("chord")
-	if m.chordsOptions.Value() != nil {
+	if m.IsDiatonic() || m.Chord() != nil {
 		label = models.SelectedStyle().Render(label)
 	}
 	return label
 }
 
+func (m model) renderModeOptions() string {
+	id := m.zonePrefix + modeOptionsId
+	return zone.Mark(id, m.modeOptions.View())
+}
+
 func (m model) renderChordsOptions() string {
+	if m.modeOptions.Value() == Diatonic {
+		id := m.zonePrefix + sizeOptionsId
+		return zone.Mark(id, m.sizeOptions.View())
+	}
 	id := m.zonePrefix + chordsOptionsId
 	return zone.Mark(id, m.chordsOptions.View())
 }
 
+func (m model) renderVoicing() string {
+	spinner := zone.Mark(m.zonePrefix+inversionSpinnerId, m.inversionSpinner.View())
+	voicing := zone.Mark(m.zonePrefix+voicingOptionsId, m.voicingOptions.View())
+	doubling := zone.Mark(m.zonePrefix+doublingCheckboxId, m.doublingCheckbox.View())
+	return lipgloss.JoinVertical(lipgloss.Left, voicing, fmt.Sprintf("inv %v %v", spinner, doubling))
+}
+
+func (m model) renderFileInput() string {
+	view := "file " + zone.Mark(m.zonePrefix+fileInputId, m.fileInput.View())
+	if m.loadErr != nil {
+		view = lipgloss.JoinVertical(lipgloss.Left, view,
+			errorStyle.Width(models.ColumnWidth-models.LabelStyle().GetWidth()).Render(m.loadErr.Error()))
+	}
+	return view
+}
+
 func (m model) renderDelaySlider() string {
 	label := "arpeggio"
 	slider := zone.Mark(m.zonePrefix+delaySliderId, m.delaySlider.View())
@@ -104,9 +282,40 @@ func (m model) renderDelaySlider() string {
 }
 
 func (m model) Chord() chords.ChordType {
-	return m.chordsOptions.Value()
+	if m.modeOptions.Value() != Fixed {
+		return nil
+	}
+	return m.voice(m.chordsOptions.Value())
+}
+
+func (m model) IsDiatonic() bool {
+	return m.modeOptions.Value() == Diatonic && m.sizeOptions.Value() != 0
+}
+
+func (m model) DiatonicChord(key scales.Key, root int) chords.ChordType {
+	if !m.IsDiatonic() {
+		return nil
+	}
+	if chord, ok := chords.Diatonic(key, root, m.sizeOptions.Value()); ok {
+		return m.voice(chord)
+	}
+	return nil
+}
+
+// voice applies the selected inversion, voicing & octave doubling to chord
+func (m model) voice(chord chords.ChordType) chords.ChordType {
+	inversion := slices.Index(chords.InversionNames(), m.inversionSpinner.Value())
+	return chords.Voice(chord, inversion, m.voicingOptions.Value(), m.doublingCheckbox.Value())
 }
 
 func (m model) ArpeggioDelay() time.Duration {
 	return delayValues[m.delaySlider.Value()]
 }
+
+func (m model) ChordTypes() []chords.ChordType {
+	return m.chordTypes
+}
+
+func (m model) Focused() bool {
+	return m.fileInput.Focused()
+}
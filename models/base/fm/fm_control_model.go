@@ -0,0 +1,293 @@
+package fm
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+
+	"github.com/HuBeZa/synth/models"
+	"github.com/HuBeZa/synth/models/base/options"
+	"github.com/HuBeZa/synth/models/base/slider"
+	"github.com/HuBeZa/synth/models/base/spinner"
+	"github.com/HuBeZa/synth/streamers"
+)
+
+const (
+	feedbackSliderRatio = 10
+
+	// bubblezone ids:
+	algorithmOptionsId = "algorithmOptions"
+	countSliderId      = "countSlider"
+	feedbackSliderId   = "feedbackSlider"
+	operatorOptionsId  = "operatorOptions"
+	ratioSpinnerId     = "ratioSpinner"
+	detuneSpinnerId    = "detuneSpinner"
+	levelSpinnerId     = "levelSpinner"
+	sustainSpinnerId   = "sustainSpinner"
+	attackSpinnerId    = "attackSpinner"
+	decaySpinnerId     = "decaySpinner"
+	releaseSpinnerId   = "releaseSpinner"
+)
+
+var (
+	ratioValues  = []float64{0.25, 0.5, 1, 1.5, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 14, 16}
+	detuneValues = []cents{-50, -25, -10, -5, -2, 0, 2, 5, 10, 25, 50}
+	gainValues   = []float64{0.0, 0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1.0}
+
+	durationValues = []time.Duration{0, 10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond, 150 * time.Millisecond,
+		200 * time.Millisecond, 250 * time.Millisecond, 300 * time.Millisecond, 350 * time.Millisecond, 400 * time.Millisecond, 450 * time.Millisecond,
+		500 * time.Millisecond, 600 * time.Millisecond, 700 * time.Millisecond, 800 * time.Millisecond, 900 * time.Millisecond, 1 * time.Second}
+
+	defaultOperators = append(streamers.DefaultFMPatch().Operators,
+		streamers.FMOperator{Ratio: 3, Level: 0.3, Sustain: 1},
+		streamers.FMOperator{Ratio: 4, Level: 0.2, Sustain: 1},
+	)
+
+	leftLabelStyle  = lipgloss.NewStyle().Width(6)
+	rightLabelStyle = lipgloss.NewStyle().Width(7).MarginLeft(2)
+)
+
+type cents float64
+
+func (c cents) String() string {
+	return fmt.Sprintf("%vc", float64(c))
+}
+
+type operator int
+
+func (o operator) Equals(other operator) bool {
+	return o == other
+}
+
+func (o operator) String() string {
+	return fmt.Sprintf("op%v", int(o)+1)
+}
+
+type Model interface {
+	tea.Model
+	Patch() streamers.FMPatch
+}
+
+type model struct {
+	algorithmOptions options.Model[streamers.FMAlgorithm]
+	countSlider      slider.Model
+	feedbackSlider   slider.Model
+	operatorOptions  options.Model[operator]
+	ratioSpinners    [streamers.MaxFMOperators]spinner.Model[float64]
+	detuneSpinners   [streamers.MaxFMOperators]spinner.Model[cents]
+	levelSpinners    [streamers.MaxFMOperators]spinner.Model[float64]
+	sustainSpinners  [streamers.MaxFMOperators]spinner.Model[float64]
+	attackSpinners   [streamers.MaxFMOperators]spinner.Model[time.Duration]
+	decaySpinners    [streamers.MaxFMOperators]spinner.Model[time.Duration]
+	releaseSpinners  [streamers.MaxFMOperators]spinner.Model[time.Duration]
+	zonePrefix       string
+	zoneHandlers     models.ZoneHandlers[model]
+}
+
+func New() Model {
+	patch := streamers.DefaultFMPatch()
+
+	m := model{}
+	m.algorithmOptions = options.New(streamers.FMAlgorithms(), false).SetValue(patch.Algorithm)
+	m.countSlider, _ = slider.New(streamers.MinFMOperators, streamers.MaxFMOperators, 1, len(patch.Operators))
+	m.feedbackSlider, _ = slider.New(0, feedbackSliderRatio, 1, int(patch.Feedback*feedbackSliderRatio), feedbackSliderRatio/2)
+	m.operatorOptions = newOperatorOptions(m.Count())
+	for i, op := range defaultOperators {
+		m.ratioSpinners[i] = spinner.New(ratioValues, false).SetValue(slices.Index(ratioValues, op.Ratio))
+		m.detuneSpinners[i] = spinner.New(detuneValues, false).SetValue(slices.Index(detuneValues, cents(op.Detune)))
+		m.levelSpinners[i] = spinner.New(gainValues, false).SetValue(slices.Index(gainValues, op.Level))
+		m.sustainSpinners[i] = spinner.New(gainValues, false).SetValue(slices.Index(gainValues, op.Sustain))
+		m.attackSpinners[i] = spinner.New(durationValues, false).SetValue(slices.Index(durationValues, op.Attack))
+		m.decaySpinners[i] = spinner.New(durationValues, false).SetValue(slices.Index(durationValues, op.Decay))
+		m.releaseSpinners[i] = spinner.New(durationValues, false).SetValue(slices.Index(durationValues, op.Release))
+	}
+
+	m.zonePrefix = zone.NewPrefix()
+	m.zoneHandlers = models.ZoneHandlers[model]{
+		m.zonePrefix + algorithmOptionsId: algorithmOptionsHandler,
+		m.zonePrefix + countSliderId:      countSliderHandler,
+		m.zonePrefix + feedbackSliderId:   feedbackSliderHandler,
+		m.zonePrefix + operatorOptionsId:  operatorOptionsHandler,
+		m.zonePrefix + ratioSpinnerId:     ratioSpinnerHandler,
+		m.zonePrefix + detuneSpinnerId:    detuneSpinnerHandler,
+		m.zonePrefix + levelSpinnerId:     levelSpinnerHandler,
+		m.zonePrefix + sustainSpinnerId:   sustainSpinnerHandler,
+		m.zonePrefix + attackSpinnerId:    attackSpinnerHandler,
+		m.zonePrefix + decaySpinnerId:     decaySpinnerHandler,
+		m.zonePrefix + releaseSpinnerId:   releaseSpinnerHandler,
+	}
+
+	return m
+}
+
+func newOperatorOptions(count int) options.Model[operator] {
+	operators := make([]operator, count)
+	for i := range operators {
+		operators[i] = operator(i)
+	}
+	return options.New(operators, false)
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		return m.zoneHandlers.Handle(m, msg)
+	}
+	return m, nil
+}
+
+func algorithmOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	optionsModel, cmd := m.algorithmOptions.Update(msg)
+	m.algorithmOptions = optionsModel.(options.Model[streamers.FMAlgorithm])
+	return m, cmd
+}
+
+func countSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.countSlider.Update(msg)
+	m.countSlider = sliderModel.(slider.Model)
+
+	// keep the selected operator, if it still exists
+	currOperator := m.operatorOptions.Value()
+	m.operatorOptions = newOperatorOptions(m.Count()).SetValue(currOperator)
+	return m, cmd
+}
+
+func feedbackSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.feedbackSlider.Update(msg)
+	m.feedbackSlider = sliderModel.(slider.Model)
+	return m, cmd
+}
+
+func operatorOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	optionsModel, cmd := m.operatorOptions.Update(msg)
+	m.operatorOptions = optionsModel.(options.Model[operator])
+	return m, cmd
+}
+
+func ratioSpinnerHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	op := m.operatorOptions.Value()
+	spinnerModel, cmd := m.ratioSpinners[op].Update(msg)
+	m.ratioSpinners[op] = spinnerModel.(spinner.Model[float64])
+	return m, cmd
+}
+
+func detuneSpinnerHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	op := m.operatorOptions.Value()
+	spinnerModel, cmd := m.detuneSpinners[op].Update(msg)
+	m.detuneSpinners[op] = spinnerModel.(spinner.Model[cents])
+	return m, cmd
+}
+
+func levelSpinnerHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	op := m.operatorOptions.Value()
+	spinnerModel, cmd := m.levelSpinners[op].Update(msg)
+	m.levelSpinners[op] = spinnerModel.(spinner.Model[float64])
+	return m, cmd
+}
+
+func sustainSpinnerHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	op := m.operatorOptions.Value()
+	spinnerModel, cmd := m.sustainSpinners[op].Update(msg)
+	m.sustainSpinners[op] = spinnerModel.(spinner.Model[float64])
+	return m, cmd
+}
+
+func attackSpinnerHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	op := m.operatorOptions.Value()
+	spinnerModel, cmd := m.attackSpinners[op].Update(msg)
+	m.attackSpinners[op] = spinnerModel.(spinner.Model[time.Duration])
+	return m, cmd
+}
+
+func decaySpinnerHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	op := m.operatorOptions.Value()
+	spinnerModel, cmd := m.decaySpinners[op].Update(msg)
+	m.decaySpinners[op] = spinnerModel.(spinner.Model[time.Duration])
+	return m, cmd
+}
+
+func releaseSpinnerHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	op := m.operatorOptions.Value()
+	spinnerModel, cmd := m.releaseSpinners[op].Update(msg)
+	m.releaseSpinners[op] = spinnerModel.(spinner.Model[time.Duration])
+	return m, cmd
+}
+
+func (m model) View() string {
+	op := m.operatorOptions.Value()
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		m.renderLabel(),
+		lipgloss.JoinVertical(lipgloss.Left,
+			m.renderAlgorithm(),
+			m.renderCountAndFeedback(),
+			m.renderOperatorOptions(),
+			m.renderOperatorRow("ratio", ratioSpinnerId, m.ratioSpinners[op].View(), "detune", detuneSpinnerId, m.detuneSpinners[op].View()),
+			m.renderOperatorRow("level", levelSpinnerId, m.levelSpinners[op].View(), "sustain", sustainSpinnerId, m.sustainSpinners[op].View()),
+			m.renderOperatorRow("A", attackSpinnerId, m.attackSpinners[op].View(), "D", decaySpinnerId, m.decaySpinners[op].View()),
+			m.renderOperatorCell("R", releaseSpinnerId, m.releaseSpinners[op].View()),
+		),
+	)
+}
+
+func (m model) renderLabel() string {
+	return models.SelectedStyle().Render(models.LabelStyle().Render("fm"))
+}
+
+func (m model) renderAlgorithm() string {
+	return zone.Mark(m.zonePrefix+algorithmOptionsId, m.algorithmOptions.View())
+}
+
+func (m model) renderCountAndFeedback() string {
+	countSlider := zone.Mark(m.zonePrefix+countSliderId, m.countSlider.View())
+	feedbackSlider := zone.Mark(m.zonePrefix+feedbackSliderId, m.feedbackSlider.View())
+	return fmt.Sprintf("%v %v %v  %v %v %v", leftLabelStyle.Render("ops"), countSlider, m.Count(), "feedback", feedbackSlider, m.Feedback())
+}
+
+func (m model) renderOperatorOptions() string {
+	return zone.Mark(m.zonePrefix+operatorOptionsId, m.operatorOptions.View())
+}
+
+func (m model) renderOperatorRow(leftLabel, leftId, leftView, rightLabel, rightId, rightView string) string {
+	right := zone.Mark(m.zonePrefix+rightId, rightView)
+	return lipgloss.JoinHorizontal(lipgloss.Top, m.renderOperatorCell(leftLabel, leftId, leftView), rightLabelStyle.Render(rightLabel), right)
+}
+
+func (m model) renderOperatorCell(label, id, view string) string {
+	return lipgloss.JoinHorizontal(lipgloss.Top, leftLabelStyle.Render(label), zone.Mark(m.zonePrefix+id, view))
+}
+
+func (m model) Count() int {
+	return m.countSlider.Value()
+}
+
+func (m model) Feedback() float64 {
+	return float64(m.feedbackSlider.Value()) / float64(feedbackSliderRatio)
+}
+
+func (m model) Patch() streamers.FMPatch {
+	patch := streamers.FMPatch{
+		Operators: make([]streamers.FMOperator, m.Count()),
+		Algorithm: m.algorithmOptions.Value(),
+		Feedback:  m.Feedback(),
+	}
+	for i := range patch.Operators {
+		patch.Operators[i] = streamers.FMOperator{
+			Ratio:   m.ratioSpinners[i].Value(),
+			Detune:  float64(m.detuneSpinners[i].Value()),
+			Level:   m.levelSpinners[i].Value(),
+			Attack:  m.attackSpinners[i].Value(),
+			Decay:   m.decaySpinners[i].Value(),
+			Sustain: m.sustainSpinners[i].Value(),
+			Release: m.releaseSpinners[i].Value(),
+		}
+	}
+	return patch
+}
@@ -0,0 +1,163 @@
+package glide
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+
+	"github.com/HuBeZa/synth/models"
+	"github.com/HuBeZa/synth/models/base/options"
+	"github.com/HuBeZa/synth/models/base/spinner"
+	"github.com/HuBeZa/synth/streamers/composers"
+)
+
+const (
+	// bubblezone ids:
+	timeSpinnerId = "timeSpinner"
+	modeOptionsId = "modeOptions"
+	typeOptionsId = "typeOptions"
+)
+
+var (
+	durationValues = []time.Duration{0, 10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond, 150 * time.Millisecond,
+		200 * time.Millisecond, 250 * time.Millisecond, 300 * time.Millisecond, 400 * time.Millisecond, 500 * time.Millisecond, 750 * time.Millisecond, 1 * time.Second}
+
+	marginLeftStyle = lipgloss.NewStyle().MarginLeft(2)
+)
+
+type Mode int
+
+const (
+	// Always glide from the previous note
+	Always Mode = iota
+	// Legato glides only when a new key is pressed while the previous key is still held
+	Legato
+)
+
+func Modes() []Mode {
+	return []Mode{Always, Legato}
+}
+
+func (m Mode) Equals(other Mode) bool {
+	return m == other
+}
+
+func (m Mode) String() string {
+	switch m {
+	case Always:
+		return "always"
+	case Legato:
+		return "legato"
+	default:
+		return strconv.Itoa(int(m))
+	}
+}
+
+type Model interface {
+	tea.Model
+	IsOn() bool
+	Duration() time.Duration
+	Mode() Mode
+	TransitionType() composers.TransitionType
+}
+
+type model struct {
+	timeSpinner  spinner.Model[time.Duration]
+	modeOptions  options.Model[Mode]
+	typeOptions  options.Model[composers.TransitionType]
+	zonePrefix   string
+	zoneHandlers models.ZoneHandlers[model]
+}
+
+func New() Model {
+	m := model{}
+	m.timeSpinner = spinner.New(durationValues, false)
+	m.modeOptions = options.New(Modes(), false)
+	m.typeOptions = options.New(composers.TransitionTypes(), false)
+	m.zonePrefix = zone.NewPrefix()
+	m.zoneHandlers = models.ZoneHandlers[model]{
+		m.zonePrefix + timeSpinnerId: timeSpinnerHandler,
+		m.zonePrefix + modeOptionsId: modeOptionsHandler,
+		m.zonePrefix + typeOptionsId: typeOptionsHandler,
+	}
+
+	return m
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		return m.zoneHandlers.Handle(m, msg)
+	}
+	return m, nil
+}
+
+func timeSpinnerHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	spinnerModel, cmd := m.timeSpinner.Update(msg)
+	m.timeSpinner = spinnerModel.(spinner.Model[time.Duration])
+	return m, cmd
+}
+
+func modeOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	optionsModel, cmd := m.modeOptions.Update(msg)
+	m.modeOptions = optionsModel.(options.Model[Mode])
+	return m, cmd
+}
+
+func typeOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	optionsModel, cmd := m.typeOptions.Update(msg)
+	m.typeOptions = optionsModel.(options.Model[composers.TransitionType])
+	return m, cmd
+}
+
+func (m model) View() string {
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		m.renderLabel(),
+		lipgloss.JoinVertical(lipgloss.Left,
+			m.renderTimeAndMode(),
+			m.renderType(),
+		),
+	)
+}
+
+func (m model) renderLabel() string {
+	label := models.LabelStyle().Render("glide")
+	if m.IsOn() {
+		label = models.SelectedStyle().Render(label)
+	}
+	return label
+}
+
+func (m model) renderTimeAndMode() string {
+	spinner := zone.Mark(m.zonePrefix+timeSpinnerId, m.timeSpinner.View())
+	mode := zone.Mark(m.zonePrefix+modeOptionsId, marginLeftStyle.Render(m.modeOptions.View()))
+	return fmt.Sprintf("%v %v", spinner, mode)
+}
+
+func (m model) renderType() string {
+	return zone.Mark(m.zonePrefix+typeOptionsId, m.typeOptions.View())
+}
+
+func (m model) IsOn() bool {
+	return m.Duration() > 0
+}
+
+func (m model) Duration() time.Duration {
+	return m.timeSpinner.Value()
+}
+
+func (m model) Mode() Mode {
+	return m.modeOptions.Value()
+}
+
+func (m model) TransitionType() composers.TransitionType {
+	return m.typeOptions.Value()
+}
@@ -21,6 +21,7 @@ type Model[T equatable[T]] interface {
 	SetValue(v T) Model[T]
 	ClearValue() Model[T]
 	SetStringer(func(T) string) Model[T]
+	SetWidth(width int) Model[T]
 }
 
 type model[T equatable[T]] struct {
@@ -28,6 +29,7 @@ type model[T equatable[T]] struct {
 	options    []T
 	allowNone  bool
 	stringer   func(T) string
+	width      int
 	zonePrefix string
 }
 
@@ -95,7 +97,9 @@ func (m model[T]) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m model[T]) View() string {
-	optionsView := make([]string, len(m.options))
+	rows := make([]string, 0, 1)
+	optionsView := make([]string, 0, len(m.options))
+	rowWidth := 0
 	for i := range m.options {
 		button := "◇"
 		style := lipgloss.NewStyle()
@@ -105,10 +109,21 @@ func (m model[T]) View() string {
 		}
 		id := m.getZoneId(i)
 		view := style.Render(fmt.Sprintf("%v %v ", button, m.stringer(m.options[i])))
-		optionsView[i] = zone.Mark(id, view)
+
+		// wrap to next row, if width is limited
+		viewWidth := lipgloss.Width(view)
+		if m.width > 0 && rowWidth > 0 && rowWidth+viewWidth > m.width {
+			rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, optionsView...))
+			optionsView = optionsView[:0]
+			rowWidth = 0
+		}
+		rowWidth += viewWidth
+
+		optionsView = append(optionsView, zone.Mark(id, view))
 	}
+	rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, optionsView...))
 
-	return lipgloss.JoinHorizontal(lipgloss.Top, optionsView...)
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
 }
 
 func (m model[T]) getZoneId(i int) string {
@@ -123,6 +138,13 @@ func (m model[T]) SetStringer(stringer func(T) string) Model[T] {
 	return m
 }
 
+// SetWidth limits the width of the view. Options overflowing the width are wrapped to a new row.
+// Zero width means unlimited.
+func (m model[T]) SetWidth(width int) Model[T] {
+	m.width = width
+	return m
+}
+
 func defaultStringer[T any](t T) string {
 	return fmt.Sprint(t)
 }
@@ -2,35 +2,78 @@ package overtones
 
 import (
 	"fmt"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	zone "github.com/lrstanley/bubblezone"
 
 	"github.com/HuBeZa/synth/models"
+	"github.com/HuBeZa/synth/models/base/options"
 	"github.com/HuBeZa/synth/models/base/slider"
+	"github.com/HuBeZa/synth/streamers"
 )
 
 const (
 	gainSliderRatio = 10
+	barsCount       = 8
+	barLevels       = 5
+	barWidth        = 4
+	barColumnWidth  = 6
 
-	countSliderId = "countSlider"
-	gainSliderId  = "gainSlider"
+	// bubblezone ids:
+	countSliderId   = "countSlider"
+	gainSliderId    = "gainSlider"
+	seriesOptionsId = "seriesOptions"
+	barCellId       = "bar%v_%v"
 )
 
 var (
-	marginRight = lipgloss.NewStyle().MarginRight(2)
+	marginRight   = lipgloss.NewStyle().MarginRight(2)
+	barStyle      = models.ForegroundColor("#87afff")
+	emptyBarStyle = models.ForegroundColor("#3a3a3a")
+
+	octaves = series{name: "octaves"}
+	// partial series of the bar-graph editor. Bell & drum ratios are the classic inharmonic modes of
+	// a tubular bell & a circular membrane.
+	allSeries = []series{
+		octaves,
+		{name: "harmonic", ratios: []float64{2, 3, 4, 5, 6, 7, 8, 9}},
+		{name: "odd", ratios: []float64{3, 5, 7, 9, 11, 13, 15, 17}},
+		{name: "bell", ratios: []float64{2.756, 5.404, 8.933, 13.344, 18.637, 24.81, 31.87, 39.81}},
+		{name: "drum", ratios: []float64{1.593, 2.135, 2.295, 2.653, 2.917, 3.155, 3.5, 3.6}},
+	}
 )
 
+// series is a set of partial frequency ratios, relative to the played note
+type series struct {
+	name   string
+	ratios []float64
+}
+
+func (s series) Equals(other series) bool {
+	return s.name == other.name
+}
+
+func (s series) String() string {
+	return s.name
+}
+
 type Model interface {
 	tea.Model
 	Count() int
 	Gain() float64
+	// IsAdditive returns true when a partial series is selected (instead of octaves)
+	IsAdditive() bool
+	Partials() []streamers.Partial
 }
 
 type model struct {
-	countSlider  slider.Model
-	gainSlider   slider.Model
+	countSlider   slider.Model
+	gainSlider    slider.Model
+	seriesOptions options.Model[series]
+	// bar levels of each series, kept while switching between series
+	levels       [][barsCount]int
 	zonePrefix   string
 	zoneHandlers models.ZoneHandlers[model]
 }
@@ -39,11 +82,19 @@ func New() Model {
 	m := model{}
 	m.countSlider, _ = slider.New(0, 4, 1, 0)
 	m.gainSlider, _ = slider.New(0, 2*gainSliderRatio, 1, gainSliderRatio, gainSliderRatio)
+	m.seriesOptions = options.New(allSeries, false)
+	m.levels = make([][barsCount]int, len(allSeries))
 
 	m.zonePrefix = zone.NewPrefix()
 	m.zoneHandlers = models.ZoneHandlers[model]{
-		m.zonePrefix + countSliderId: countSliderHandler,
-		m.zonePrefix + gainSliderId:  gainSliderHandler,
+		m.zonePrefix + countSliderId:   countSliderHandler,
+		m.zonePrefix + gainSliderId:    gainSliderHandler,
+		m.zonePrefix + seriesOptionsId: seriesOptionsHandler,
+	}
+	for bar := range barsCount {
+		for level := 1; level <= barLevels; level++ {
+			m.zoneHandlers[m.zonePrefix+fmt.Sprintf(barCellId, bar, level)] = barCellHandler(bar, level)
+		}
 	}
 
 	return m
@@ -73,22 +124,65 @@ func gainSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+func seriesOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	optionsModel, cmd := m.seriesOptions.Update(msg)
+	m.seriesOptions = optionsModel.(options.Model[series])
+	return m, cmd
+}
+
+// barCellHandler sets the bar to the clicked level. Clicking the top of the bar lowers it by one level,
+// so a bar can be cleared by clicking its bottom cell.
+func barCellHandler(bar, level int) models.ZoneHandler[model] {
+	return func(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+		if msg.Action != tea.MouseActionRelease || msg.Button != tea.MouseButtonLeft || !m.IsAdditive() {
+			return m, nil
+		}
+
+		// levels is shared between copies of the model, so it is copied before the change
+		levels := make([][barsCount]int, len(m.levels))
+		copy(levels, m.levels)
+
+		idx := m.seriesIndex()
+		if levels[idx][bar] == level {
+			level--
+		}
+		levels[idx][bar] = level
+		m.levels = levels
+		return m, nil
+	}
+}
+
 func (m model) View() string {
-	return lipgloss.JoinHorizontal(lipgloss.Top,
+	header := lipgloss.JoinHorizontal(lipgloss.Top,
 		marginRight.Render(m.renderLabel()),
-		marginRight.Render(m.renderCount()),
-		m.renderGain(),
+		zone.Mark(m.zonePrefix+seriesOptionsId, m.seriesOptions.View()),
 	)
+	if !m.IsAdditive() {
+		return lipgloss.JoinVertical(lipgloss.Left, header, m.renderOctaves())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, header, m.renderBars())
 }
 
 func (m model) renderLabel() string {
 	label := "overtones"
-	if m.Count() > 0 && m.Gain() > 0 {
+	active := m.Count() > 0 && m.Gain() > 0
+	if m.IsAdditive() {
+		active = len(m.Partials()) > 0
+	}
+	if active {
 		label = models.SelectedStyle().Render(label)
 	}
 	return label
 }
 
+func (m model) renderOctaves() string {
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		models.LabelStyle().Render("count"),
+		marginRight.Render(m.renderCount()),
+		m.renderGain(),
+	)
+}
+
 func (m model) renderCount() string {
 	slider := zone.Mark(m.zonePrefix+countSliderId, m.countSlider.View())
 	val := m.Count()
@@ -102,6 +196,45 @@ func (m model) renderGain() string {
 	return fmt.Sprintf("%v %v %v", label, slider, val)
 }
 
+// renderBars draws the bar-graph editor, from the top level down, with the partial ratios underneath
+func (m model) renderBars() string {
+	levels := m.levels[m.seriesIndex()]
+	lines := make([]string, 0, barLevels+1)
+
+	for level := barLevels; level > 0; level-- {
+		var sb strings.Builder
+		sb.WriteString(models.LabelStyle().Render(fmt.Sprintf("%v%%", 100*level/barLevels)))
+		for bar := range barsCount {
+			cell := emptyBarStyle.Render(strings.Repeat("░", barWidth))
+			if levels[bar] >= level {
+				cell = barStyle.Render(strings.Repeat("█", barWidth))
+			}
+			sb.WriteString(zone.Mark(m.zonePrefix+fmt.Sprintf(barCellId, bar, level), cell))
+			sb.WriteString(strings.Repeat(" ", barColumnWidth-barWidth))
+		}
+		lines = append(lines, sb.String())
+	}
+
+	var sb strings.Builder
+	sb.WriteString(models.LabelStyle().Render("ratio"))
+	for _, ratio := range m.seriesOptions.Value().ratios {
+		sb.WriteString(fmt.Sprintf("%-*.4g", barColumnWidth, ratio))
+	}
+	lines = append(lines, sb.String())
+
+	return strings.Join(lines, "\n")
+}
+
+func (m model) seriesIndex() int {
+	current := m.seriesOptions.Value()
+	for i, s := range allSeries {
+		if s.Equals(current) {
+			return i
+		}
+	}
+	return 0
+}
+
 func (m model) Count() int {
 	return m.countSlider.Value()
 }
@@ -109,3 +242,26 @@ func (m model) Count() int {
 func (m model) Gain() float64 {
 	return float64(m.gainSlider.Value()) / float64(gainSliderRatio)
 }
+
+func (m model) IsAdditive() bool {
+	return !m.seriesOptions.Value().Equals(octaves)
+}
+
+// Partials returns the partials of the selected series with a non-zero amplitude
+func (m model) Partials() []streamers.Partial {
+	if !m.IsAdditive() {
+		return nil
+	}
+
+	levels := m.levels[m.seriesIndex()]
+	var partials []streamers.Partial
+	for i, ratio := range m.seriesOptions.Value().ratios {
+		if levels[i] > 0 {
+			partials = append(partials, streamers.Partial{
+				Ratio:     ratio,
+				Amplitude: float64(levels[i]) / barLevels,
+			})
+		}
+	}
+	return partials
+}
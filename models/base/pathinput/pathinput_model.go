@@ -0,0 +1,108 @@
+package pathinput
+
+import (
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+
+	"github.com/HuBeZa/synth/models"
+)
+
+var (
+	blurredStyle = lipgloss.NewStyle().Underline(true)
+	focusedStyle = models.SelectedStyle().Underline(true)
+)
+
+// Model is a single line file path input. Clicking it focuses it, enter submits the path & blurs it, and esc reverts it.
+// While focused, the parent model should forward all key presses to it.
+type Model interface {
+	tea.Model
+	Focused() bool
+	Value() string
+	SetValue(value string) Model
+}
+
+type model struct {
+	input     textinput.Model
+	lastValue string
+	zoneId    string
+}
+
+func New(placeholder string, width int) Model {
+	input := textinput.New()
+	input.Prompt = ""
+	input.Placeholder = placeholder
+	input.Width = width
+	input.Cursor.SetMode(cursor.CursorStatic)
+
+	return model{
+		input:  input,
+		zoneId: zone.NewPrefix(),
+	}
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		if msg.Action != tea.MouseActionRelease || msg.Button != tea.MouseButtonLeft {
+			return m, nil
+		}
+
+		if zone.Get(m.zoneId).InBounds(msg) && !m.input.Focused() {
+			m.lastValue = m.input.Value()
+			return m, m.input.Focus()
+		}
+	case tea.KeyMsg:
+		if !m.input.Focused() {
+			return m, nil
+		}
+
+		switch msg.Type {
+		case tea.KeyEnter:
+			m.input.Blur()
+			m.lastValue = m.input.Value()
+			return m, nil
+		case tea.KeyEsc:
+			m.input.Blur()
+			m.input.SetValue(m.lastValue)
+			return m, nil
+		}
+
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m model) View() string {
+	style := blurredStyle
+	if m.input.Focused() {
+		style = focusedStyle
+	}
+	return zone.Mark(m.zoneId, style.Render(m.input.View()))
+}
+
+func (m model) Focused() bool {
+	return m.input.Focused()
+}
+
+// Value returns the submitted path. While focused, it is the path before the edit.
+func (m model) Value() string {
+	if m.input.Focused() {
+		return m.lastValue
+	}
+	return m.input.Value()
+}
+
+func (m model) SetValue(value string) Model {
+	m.input.SetValue(value)
+	m.lastValue = value
+	return m
+}
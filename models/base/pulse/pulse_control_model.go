@@ -0,0 +1,129 @@
+package pulse
+
+import (
+	"fmt"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	zone "github.com/lrstanley/bubblezone"
+
+	"github.com/HuBeZa/synth/models"
+	"github.com/HuBeZa/synth/models/base/slider"
+	"github.com/HuBeZa/synth/models/base/spinner"
+	"github.com/HuBeZa/synth/streamers"
+)
+
+const (
+	depthSliderStep = 5
+
+	// bubblezone ids:
+	widthSliderId = "widthSlider"
+	rateSpinnerId = "rateSpinner"
+	depthSliderId = "depthSlider"
+)
+
+var (
+	widthValues = []int{1, 2, 3, 5, 10, 15, 20, 25, 30, 35, 40, 45, 50, 55, 60, 65, 70, 75, 80, 85, 90, 95, 97, 98, 99}
+	rateValues  = []rate{0, 0.1, 0.2, 0.5, 1, 2, 3, 5, 8, 13}
+)
+
+// rate is the PWM LFO rate, in Hz
+type rate float64
+
+func (r rate) String() string {
+	if r == 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%vHz", float64(r))
+}
+
+type Model interface {
+	tea.Model
+	PulseWidth() streamers.PulseWidth
+}
+
+type model struct {
+	widthSlider  slider.Model
+	rateSpinner  spinner.Model[rate]
+	depthSlider  slider.Model
+	zonePrefix   string
+	zoneHandlers models.ZoneHandlers[model]
+}
+
+func New() Model {
+	m := model{}
+	mid := slices.Index(widthValues, 50)
+	m.widthSlider, _ = slider.New(0, len(widthValues)-1, 1, mid, mid)
+	m.rateSpinner = spinner.New(rateValues, false)
+	m.depthSlider, _ = slider.New(0, 45, depthSliderStep, 20)
+	m.zonePrefix = zone.NewPrefix()
+	m.zoneHandlers = models.ZoneHandlers[model]{
+		m.zonePrefix + widthSliderId: widthSliderHandler,
+		m.zonePrefix + rateSpinnerId: rateSpinnerHandler,
+		m.zonePrefix + depthSliderId: depthSliderHandler,
+	}
+
+	return m
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		return m.zoneHandlers.Handle(m, msg)
+	}
+	return m, nil
+}
+
+func widthSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.widthSlider.Update(msg)
+	m.widthSlider = sliderModel.(slider.Model)
+	return m, cmd
+}
+
+func rateSpinnerHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	spinnerModel, cmd := m.rateSpinner.Update(msg)
+	m.rateSpinner = spinnerModel.(spinner.Model[rate])
+	return m, cmd
+}
+
+func depthSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.depthSlider.Update(msg)
+	m.depthSlider = sliderModel.(slider.Model)
+	return m, cmd
+}
+
+func (m model) View() string {
+	return fmt.Sprintf("%v\n%v", m.renderWidth(), m.renderPWM())
+}
+
+func (m model) renderWidth() string {
+	label := models.LabelStyle().Render("width")
+	slider := zone.Mark(m.zonePrefix+widthSliderId, m.widthSlider.View())
+	return fmt.Sprintf("%v%v %v%%", label, slider, m.width())
+}
+
+func (m model) renderPWM() string {
+	label := models.LabelStyle().Render("pwm")
+	spinner := zone.Mark(m.zonePrefix+rateSpinnerId, m.rateSpinner.View())
+	depthLabel := models.LabelStyle().MarginLeft(2).Render("depth")
+	slider := zone.Mark(m.zonePrefix+depthSliderId, m.depthSlider.View())
+	return fmt.Sprintf("%v%v%v%v ±%v%%", label, spinner, depthLabel, slider, m.depthSlider.Value())
+}
+
+// width returns the pulse width in percents
+func (m model) width() int {
+	return widthValues[m.widthSlider.Value()]
+}
+
+func (m model) PulseWidth() streamers.PulseWidth {
+	pulseWidth := streamers.PulseWidth{Width: float64(m.width()) / 100}
+	if rate := m.rateSpinner.Value(); rate > 0 {
+		pulseWidth.LFORate = float64(rate)
+		pulseWidth.LFODepth = float64(m.depthSlider.Value()) / 100
+	}
+	return pulseWidth
+}
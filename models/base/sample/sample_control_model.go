@@ -0,0 +1,264 @@
+package sample
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+
+	"github.com/HuBeZa/synth/models"
+	"github.com/HuBeZa/synth/models/base/options"
+	"github.com/HuBeZa/synth/models/base/pathinput"
+	"github.com/HuBeZa/synth/models/base/slider"
+	"github.com/HuBeZa/synth/models/base/spinner"
+	"github.com/HuBeZa/synth/streamers"
+	"github.com/HuBeZa/synth/streamers/frequencies"
+)
+
+const (
+	loopSliderRatio = 20
+	pathInputWidth  = 32
+
+	// bubblezone ids:
+	pathInputId   = "pathInput"
+	rootSpinnerId = "rootSpinner"
+	modeOptionsId = "modeOptions"
+	startSliderId = "startSlider"
+	endSliderId   = "endSlider"
+	velSliderId   = "velSlider"
+)
+
+var (
+	rootValues     = frequencies.GetRange(frequencies.C1(), frequencies.C7())
+	velocityValues = []int{1, 16, 32, 48, 64, 80, 96, 100, 112, streamers.MaxSfzVelocity}
+	errorStyle     = models.ForegroundColor("#DF0000")
+)
+
+// Model loads either a single sample (WAV, FLAC or MP3), or a multi-sampled SFZ instrument
+type Model interface {
+	tea.Model
+	Focused() bool
+	Name() string
+	Generator() streamers.StreamerGeneratorFunc
+}
+
+type model struct {
+	pathInput    pathinput.Model
+	rootSpinner  spinner.Model[frequencies.Frequency]
+	modeOptions  options.Model[streamers.LoopMode]
+	startSlider  slider.Model
+	endSlider    slider.Model
+	velSlider    slider.Model
+	sample       *streamers.Sample
+	instrument   *streamers.SfzInstrument
+	loadErr      error
+	zonePrefix   string
+	zoneHandlers models.ZoneHandlers[model]
+}
+
+func New() Model {
+	m := model{}
+	m.pathInput = pathinput.New("built-in (click to load a file)", pathInputWidth)
+	m.rootSpinner = spinner.New(rootValues, false).SetValue(slices.Index(rootValues, frequencies.C4()))
+	m.modeOptions = options.New(streamers.LoopModes(), false)
+	m.startSlider, _ = slider.New(0, loopSliderRatio, 1, 0)
+	m.endSlider, _ = slider.New(0, loopSliderRatio, 1, loopSliderRatio)
+	velocity := slices.Index(velocityValues, streamers.DefaultSfzVelocity)
+	m.velSlider, _ = slider.New(0, len(velocityValues)-1, 1, velocity, velocity)
+	m.sample = streamers.BuiltinSample()
+	m.zonePrefix = zone.NewPrefix()
+	m.zoneHandlers = models.ZoneHandlers[model]{
+		m.zonePrefix + pathInputId:   pathInputHandler,
+		m.zonePrefix + rootSpinnerId: rootSpinnerHandler,
+		m.zonePrefix + modeOptionsId: modeOptionsHandler,
+		m.zonePrefix + startSliderId: startSliderHandler,
+		m.zonePrefix + endSliderId:   endSliderHandler,
+		m.zonePrefix + velSliderId:   velSliderHandler,
+	}
+
+	return m
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		return m.zoneHandlers.Handle(m, msg)
+	case tea.KeyMsg:
+		if !m.pathInput.Focused() {
+			return m, nil
+		}
+
+		inputModel, cmd := m.pathInput.Update(msg)
+		m.pathInput = inputModel.(pathinput.Model)
+		if !m.pathInput.Focused() {
+			m.loadSample()
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+func pathInputHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	inputModel, cmd := m.pathInput.Update(msg)
+	m.pathInput = inputModel.(pathinput.Model)
+	return m, cmd
+}
+
+func rootSpinnerHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	spinnerModel, cmd := m.rootSpinner.Update(msg)
+	m.rootSpinner = spinnerModel.(spinner.Model[frequencies.Frequency])
+	return m, cmd
+}
+
+func modeOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	optionsModel, cmd := m.modeOptions.Update(msg)
+	m.modeOptions = optionsModel.(options.Model[streamers.LoopMode])
+	return m, cmd
+}
+
+// startSliderHandler & endSliderHandler keep the loop start before its end, by pushing the other slider
+func startSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.startSlider.Update(msg)
+	m.startSlider = sliderModel.(slider.Model)
+	if start := m.startSlider.Value(); start >= m.endSlider.Value() {
+		end := min(start+1, loopSliderRatio)
+		m.endSlider, _ = m.endSlider.SetValue(end)
+		m.startSlider, _ = m.startSlider.SetValue(end - 1)
+	}
+	return m, cmd
+}
+
+func endSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.endSlider.Update(msg)
+	m.endSlider = sliderModel.(slider.Model)
+	if end := m.endSlider.Value(); end <= m.startSlider.Value() {
+		start := max(end-1, 0)
+		m.startSlider, _ = m.startSlider.SetValue(start)
+		m.endSlider, _ = m.endSlider.SetValue(start + 1)
+	}
+	return m, cmd
+}
+
+func velSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.velSlider.Update(msg)
+	m.velSlider = sliderModel.(slider.Model)
+	return m, cmd
+}
+
+// loadSample loads the submitted file. An empty path loads the built-in sample.
+func (m *model) loadSample() {
+	path := strings.TrimSpace(m.pathInput.Value())
+	if path == "" {
+		m.sample, m.instrument, m.loadErr = streamers.BuiltinSample(), nil, nil
+		return
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".sfz") {
+		instrument, err := streamers.LoadSfzInstrument(path)
+		if err != nil {
+			m.loadErr = err
+			return
+		}
+		m.instrument, m.loadErr = instrument, nil
+		return
+	}
+
+	sample, err := streamers.LoadSample(path)
+	if err != nil {
+		m.loadErr = err
+		return
+	}
+	m.sample, m.instrument, m.loadErr = sample, nil, nil
+}
+
+func (m model) View() string {
+	views := []string{m.renderSample()}
+	if m.instrument != nil {
+		views = append(views, m.renderInstrumentInfo(), m.renderVelocity())
+	} else {
+		views = append(views, m.renderInfo(), m.renderMode())
+		if m.modeOptions.Value() != streamers.OneShot {
+			views = append(views, m.renderLoop())
+		}
+	}
+	if m.loadErr != nil {
+		views = append(views, errorStyle.Width(models.ColumnWidth).Render(m.loadErr.Error()))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, views...)
+}
+
+func (m model) renderSample() string {
+	label := models.LabelStyle().Render("sample")
+	input := zone.Mark(m.zonePrefix+pathInputId, m.pathInput.View())
+	return label + input
+}
+
+func (m model) renderInfo() string {
+	label := models.LabelStyle().Render("root")
+	spinner := zone.Mark(m.zonePrefix+rootSpinnerId, m.rootSpinner.View())
+	return fmt.Sprintf("%v%v %v, %vHz", label, spinner, m.sample.Duration().Round(10*time.Millisecond), m.sample.SampleRate())
+}
+
+func (m model) renderInstrumentInfo() string {
+	label := models.LabelStyle().Render("sfz")
+	return fmt.Sprintf("%v%v regions, %v round robin", label, len(m.instrument.Regions()), m.instrument.SeqLength())
+}
+
+func (m model) renderVelocity() string {
+	label := models.LabelStyle().Render("vel")
+	slider := zone.Mark(m.zonePrefix+velSliderId, m.velSlider.View())
+	return fmt.Sprintf("%v%v %v", label, slider, m.velocity())
+}
+
+func (m model) renderMode() string {
+	label := models.LabelStyle().Render("mode")
+	return label + zone.Mark(m.zonePrefix+modeOptionsId, m.modeOptions.View())
+}
+
+func (m model) renderLoop() string {
+	label := models.LabelStyle().Render("loop")
+	start := zone.Mark(m.zonePrefix+startSliderId, m.startSlider.View())
+	end := zone.Mark(m.zonePrefix+endSliderId, m.endSlider.View())
+	return fmt.Sprintf("%v%v %v", label, start, end)
+}
+
+func (m model) Focused() bool {
+	return m.pathInput.Focused()
+}
+
+func (m model) Name() string {
+	if m.instrument != nil {
+		return m.instrument.Name()
+	}
+	return m.sample.Name()
+}
+
+func (m model) Generator() streamers.StreamerGeneratorFunc {
+	if m.instrument != nil {
+		return streamers.NewSfzGenerator(m.instrument, m.velocity())
+	}
+	return streamers.NewSampleGenerator(m.sample, m.rootSpinner.Value().Frequency(), m.loop())
+}
+
+func (m model) velocity() int {
+	return velocityValues[m.velSlider.Value()]
+}
+
+// loop returns the loop mode, and the loop points in frames of the current sample
+func (m model) loop() streamers.SampleLoop {
+	length := m.sample.Len()
+	return streamers.SampleLoop{
+		Mode:  m.modeOptions.Value(),
+		Start: length * m.startSlider.Value() / loopSliderRatio,
+		End:   length * m.endSlider.Value() / loopSliderRatio,
+	}
+}
@@ -0,0 +1,225 @@
+package scale
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+
+	"github.com/HuBeZa/synth/models"
+	"github.com/HuBeZa/synth/models/base/options"
+	"github.com/HuBeZa/synth/models/base/spinner"
+	"github.com/HuBeZa/synth/streamers/scales"
+)
+
+const (
+	// bubblezone ids:
+	scaleOptionsId = "scaleOptions"
+	rootSpinnerId  = "rootSpinner"
+	modeOptionsId  = "modeOptions"
+	customCellId   = "customCell%v"
+)
+
+var (
+	rootNames       = scales.NoteNames()
+	marginLeftStyle = lipgloss.NewStyle().MarginLeft(2)
+	cellStyle       = lipgloss.NewStyle().Width(3)
+)
+
+type Mode int
+
+const (
+	// Lock maps the keys to the notes of the scale only
+	Lock Mode = iota
+	// Highlight keeps all notes, and marks the notes out of the scale
+	Highlight
+)
+
+func Modes() []Mode {
+	return []Mode{Lock, Highlight}
+}
+
+func (m Mode) Equals(other Mode) bool {
+	return m == other
+}
+
+func (m Mode) String() string {
+	switch m {
+	case Lock:
+		return "lock"
+	case Highlight:
+		return "highlight"
+	default:
+		return strconv.Itoa(int(m))
+	}
+}
+
+type Model interface {
+	tea.Model
+	// Key returns the root & the selected scale, or false if no scale is selected
+	Key() (scales.Key, bool)
+	Mode() Mode
+}
+
+type model struct {
+	scaleOptions options.Model[scales.Scale]
+	rootSpinner  spinner.Model[string]
+	modeOptions  options.Model[Mode]
+	// semitones of the custom scale, in addition to the root
+	custom       [12]bool
+	zonePrefix   string
+	zoneHandlers models.ZoneHandlers[model]
+}
+
+func New() Model {
+	m := model{}
+	m.custom = [12]bool{true}
+	m.scaleOptions = m.newScaleOptions()
+	m.rootSpinner = spinner.New(rootNames, true)
+	m.modeOptions = options.New(Modes(), false)
+	m.zonePrefix = zone.NewPrefix()
+	m.zoneHandlers = models.ZoneHandlers[model]{
+		m.zonePrefix + scaleOptionsId: scaleOptionsHandler,
+		m.zonePrefix + rootSpinnerId:  rootSpinnerHandler,
+		m.zonePrefix + modeOptionsId:  modeOptionsHandler,
+	}
+	// the root cell is not clickable, as the root is always in the scale
+	for semitone := 1; semitone < len(m.custom); semitone++ {
+		m.zoneHandlers[m.zonePrefix+fmt.Sprintf(customCellId, semitone)] = customCellHandler(semitone)
+	}
+
+	return m
+}
+
+// newScaleOptions returns the built-in scales & the custom scale, keeping the current selection
+func (m model) newScaleOptions() options.Model[scales.Scale] {
+	semitones := make([]int, 0, len(m.custom))
+	for semitone, on := range m.custom {
+		if on {
+			semitones = append(semitones, semitone)
+		}
+	}
+	custom, err := scales.NewCustomScale(semitones)
+	if err != nil {
+		panic(err)
+	}
+
+	scaleOptions := options.New(append(scales.Scales(), custom), true).SetWidth(models.ColumnWidth - models.LabelStyle().GetWidth())
+	if m.scaleOptions != nil && m.scaleOptions.Value() != nil {
+		scaleOptions = scaleOptions.SetValue(m.scaleOptions.Value())
+	}
+	return scaleOptions
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		return m.zoneHandlers.Handle(m, msg)
+	}
+	return m, nil
+}
+
+func scaleOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	optionsModel, cmd := m.scaleOptions.Update(msg)
+	m.scaleOptions = optionsModel.(options.Model[scales.Scale])
+	return m, cmd
+}
+
+func rootSpinnerHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	spinnerModel, cmd := m.rootSpinner.Update(msg)
+	m.rootSpinner = spinnerModel.(spinner.Model[string])
+	return m, cmd
+}
+
+func modeOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	optionsModel, cmd := m.modeOptions.Update(msg)
+	m.modeOptions = optionsModel.(options.Model[Mode])
+	return m, cmd
+}
+
+// customCellHandler toggles the semitone in the custom scale
+func customCellHandler(semitone int) models.ZoneHandler[model] {
+	return func(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+		if msg.Action != tea.MouseActionRelease || msg.Button != tea.MouseButtonLeft || !m.isCustom() {
+			return m, nil
+		}
+
+		m.custom[semitone] = !m.custom[semitone]
+		m.scaleOptions = m.newScaleOptions()
+		return m, nil
+	}
+}
+
+func (m model) View() string {
+	views := []string{
+		zone.Mark(m.zonePrefix+scaleOptionsId, m.scaleOptions.View()),
+		m.renderRootAndMode(),
+	}
+	if m.isCustom() {
+		views = append(views, m.renderCustomCells())
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		m.renderLabel(),
+		lipgloss.JoinVertical(lipgloss.Left, views...))
+}
+
+func (m model) renderLabel() string {
+	label := models.LabelStyle().Render("scale")
+	if m.scaleOptions.Value() != nil {
+		label = models.SelectedStyle().Render(label)
+	}
+	return label
+}
+
+func (m model) renderRootAndMode() string {
+	spinner := zone.Mark(m.zonePrefix+rootSpinnerId, m.rootSpinner.View())
+	mode := zone.Mark(m.zonePrefix+modeOptionsId, marginLeftStyle.Render(m.modeOptions.View()))
+	return fmt.Sprintf("root %v %v", spinner, mode)
+}
+
+// renderCustomCells renders the semitones names, relative to the root, where the custom scale semitones are selected
+func (m model) renderCustomCells() string {
+	root := m.root()
+	var sb strings.Builder
+	for semitone, on := range m.custom {
+		style := cellStyle
+		if on {
+			style = models.SelectedStyle().Inherit(cellStyle)
+		}
+		cell := style.Render(rootNames[(root+semitone)%len(rootNames)])
+		sb.WriteString(zone.Mark(m.zonePrefix+fmt.Sprintf(customCellId, semitone), cell))
+	}
+	return sb.String()
+}
+
+func (m model) isCustom() bool {
+	return m.scaleOptions.Value() != nil && m.scaleOptions.Value().Name() == "custom"
+}
+
+func (m model) root() int {
+	for i, name := range rootNames {
+		if name == m.rootSpinner.Value() {
+			return i
+		}
+	}
+	return 0
+}
+
+func (m model) Key() (scales.Key, bool) {
+	if m.scaleOptions.Value() == nil {
+		return scales.Key{}, false
+	}
+	return scales.Key{Root: m.root(), Scale: m.scaleOptions.Value()}, true
+}
+
+func (m model) Mode() Mode {
+	return m.modeOptions.Value()
+}
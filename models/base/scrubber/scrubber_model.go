@@ -0,0 +1,123 @@
+package scrubber
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+
+	"github.com/HuBeZa/synth/models"
+)
+
+const (
+	positionMarker = "▲"
+	jitterMarker   = "─"
+)
+
+var overviewLevels = []rune("▁▂▃▄▅▆▇█")
+
+// Model is a position bar over a source overview. Clicking or dragging along it moves the position.
+type Model interface {
+	tea.Model
+	// Value returns the position, 0 to 1
+	Value() float64
+	SetValue(val float64) Model
+	// SetJitter marks the range of jitter around the position, 0 to 1
+	SetJitter(jitter float64) Model
+	// SetOverview sets the peaks drawn above the bar, 0 to 1 each. nil draws a flat line.
+	SetOverview(peaks []float64) Model
+}
+
+type model struct {
+	width  int
+	cursor int
+	jitter float64
+	peaks  []float64
+	zoneId string
+}
+
+func New(width int) Model {
+	return model{
+		width:  width,
+		cursor: (width - 1) / 2,
+		zoneId: zone.NewPrefix(),
+	}
+}
+
+func (m model) Value() float64 {
+	return float64(m.cursor) / float64(m.width-1)
+}
+
+func (m model) SetValue(val float64) Model {
+	val = min(max(val, 0), 1)
+	m.cursor = int(val*float64(m.width-1) + 0.5)
+	return m
+}
+
+func (m model) SetJitter(jitter float64) Model {
+	m.jitter = min(max(jitter, 0), 1)
+	return m
+}
+
+func (m model) SetOverview(peaks []float64) Model {
+	m.peaks = peaks
+	return m
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		if msg.Button != tea.MouseButtonLeft {
+			return m, nil
+		}
+
+		if z := zone.Get(m.zoneId); z.InBounds(msg) {
+			x, _ := z.Pos(msg)
+			m.cursor = min(max(x, 0), m.width-1)
+		}
+	}
+	return m, nil
+}
+
+func (m model) View() string {
+	jitter := int(m.jitter*float64(m.width-1) + 0.5)
+
+	var overview, markers strings.Builder
+	for i := range m.width {
+		cell := m.overviewCell(i)
+		if i >= m.cursor-jitter && i <= m.cursor+jitter {
+			cell = models.SelectedStyle().Render(cell)
+		}
+		overview.WriteString(cell)
+
+		switch {
+		case i == m.cursor:
+			markers.WriteString(models.SelectedStyle().Render(positionMarker))
+		case i >= m.cursor-jitter && i <= m.cursor+jitter:
+			markers.WriteString(jitterMarker)
+		default:
+			markers.WriteString(" ")
+		}
+	}
+
+	return zone.Mark(m.zoneId, lipgloss.JoinVertical(lipgloss.Left, overview.String(), markers.String()))
+}
+
+// overviewCell returns the overview level of the i'th column
+func (m model) overviewCell(i int) string {
+	if len(m.peaks) == 0 {
+		return string(overviewLevels[0])
+	}
+
+	peak := 0.0
+	for _, p := range m.peaks[i*len(m.peaks)/m.width : max((i+1)*len(m.peaks)/m.width, i*len(m.peaks)/m.width+1)] {
+		peak = max(peak, p)
+	}
+	level := int(min(max(peak, 0), 1) * float64(len(overviewLevels)-1))
+	return string(overviewLevels[level])
+}
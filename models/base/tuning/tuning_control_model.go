@@ -0,0 +1,203 @@
+package tuning
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+
+	"github.com/HuBeZa/synth/models"
+	"github.com/HuBeZa/synth/models/base/options"
+	"github.com/HuBeZa/synth/models/base/pathinput"
+	"github.com/HuBeZa/synth/models/base/spinner"
+	"github.com/HuBeZa/synth/streamers/frequencies"
+)
+
+const (
+	sclInputWidth = 20
+	kbmInputWidth = 18
+
+	// bubblezone ids:
+	tuningOptionsId    = "tuningOptions"
+	referenceSpinnerId = "referenceSpinner"
+	sclInputId         = "sclInput"
+	kbmInputId         = "kbmInput"
+)
+
+var (
+	referenceValues = []float64{415, 430, 432, 435, 440, 442, 443, 444, 466}
+	errorStyle      = models.ForegroundColor("#DF0000")
+)
+
+// Model selects a built-in tuning or a Scala tuning (.scl scale, with an optional .kbm keyboard mapping)
+type Model interface {
+	tea.Model
+	Focused() bool
+	Tuning() frequencies.Tuning
+}
+
+type model struct {
+	tuningOptions    options.Model[frequencies.Tuning]
+	referenceSpinner spinner.Model[float64]
+	sclInput         pathinput.Model
+	kbmInput         pathinput.Model
+	// scala is the loaded Scala tuning, or nil
+	scala        frequencies.Tuning
+	hasKbm       bool
+	loadErr      error
+	zonePrefix   string
+	zoneHandlers models.ZoneHandlers[model]
+}
+
+func New() Model {
+	m := model{}
+	m.tuningOptions = newTuningOptions(nil)
+	m.referenceSpinner = spinner.New(referenceValues, false).SetValue(slices.Index(referenceValues, frequencies.DefaultReference))
+	m.sclInput = pathinput.New("click to load .scl", sclInputWidth)
+	m.kbmInput = pathinput.New("optional .kbm", kbmInputWidth)
+	m.zonePrefix = zone.NewPrefix()
+	m.zoneHandlers = models.ZoneHandlers[model]{
+		m.zonePrefix + tuningOptionsId:    tuningOptionsHandler,
+		m.zonePrefix + referenceSpinnerId: referenceSpinnerHandler,
+		m.zonePrefix + sclInputId:         sclInputHandler,
+		m.zonePrefix + kbmInputId:         kbmInputHandler,
+	}
+
+	return m
+}
+
+func newTuningOptions(scala frequencies.Tuning) options.Model[frequencies.Tuning] {
+	tunings := frequencies.Tunings()
+	if scala != nil {
+		tunings = append(tunings, scala)
+	}
+	return options.New(tunings, false).SetWidth(models.ColumnWidth - models.LabelStyle().GetWidth())
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		return m.zoneHandlers.Handle(m, msg)
+	case tea.KeyMsg:
+		var cmd tea.Cmd
+		var inputModel tea.Model
+		switch {
+		case m.sclInput.Focused():
+			inputModel, cmd = m.sclInput.Update(msg)
+			m.sclInput = inputModel.(pathinput.Model)
+		case m.kbmInput.Focused():
+			inputModel, cmd = m.kbmInput.Update(msg)
+			m.kbmInput = inputModel.(pathinput.Model)
+		default:
+			return m, nil
+		}
+
+		if !m.Focused() {
+			m.loadScala()
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+func tuningOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	optionsModel, cmd := m.tuningOptions.Update(msg)
+	m.tuningOptions = optionsModel.(options.Model[frequencies.Tuning])
+	return m, cmd
+}
+
+func referenceSpinnerHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	spinnerModel, cmd := m.referenceSpinner.Update(msg)
+	m.referenceSpinner = spinnerModel.(spinner.Model[float64])
+	return m, cmd
+}
+
+func sclInputHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	inputModel, cmd := m.sclInput.Update(msg)
+	m.sclInput = inputModel.(pathinput.Model)
+	return m, cmd
+}
+
+func kbmInputHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	inputModel, cmd := m.kbmInput.Update(msg)
+	m.kbmInput = inputModel.(pathinput.Model)
+	return m, cmd
+}
+
+// loadScala loads the submitted .scl & .kbm files, and selects the loaded tuning.
+// An empty .scl path removes the Scala tuning.
+func (m *model) loadScala() {
+	sclPath, kbmPath := strings.TrimSpace(m.sclInput.Value()), strings.TrimSpace(m.kbmInput.Value())
+	selected := m.tuningOptions.Value()
+	if sclPath == "" {
+		m.scala, m.hasKbm, m.loadErr = nil, false, nil
+		m.tuningOptions = newTuningOptions(nil).SetValue(selected)
+		return
+	}
+
+	scala, err := frequencies.LoadScalaTuning(sclPath, kbmPath)
+	if err != nil {
+		m.loadErr = err
+		return
+	}
+	m.scala, m.hasKbm, m.loadErr = scala, kbmPath != "", nil
+	m.tuningOptions = newTuningOptions(scala).SetValue(scala)
+}
+
+func (m model) View() string {
+	views := []string{
+		zone.Mark(m.zonePrefix+tuningOptionsId, m.tuningOptions.View()),
+	}
+	if !m.isKbmSelected() {
+		views = append(views, m.renderReference())
+	}
+	views = append(views, m.renderScala())
+	if m.loadErr != nil {
+		views = append(views, errorStyle.Width(models.ColumnWidth-models.LabelStyle().GetWidth()).Render(m.loadErr.Error()))
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		models.LabelStyle().Render("tuning"),
+		lipgloss.JoinVertical(lipgloss.Left, views...),
+	)
+}
+
+func (m model) renderReference() string {
+	spinner := zone.Mark(m.zonePrefix+referenceSpinnerId, m.referenceSpinner.View())
+	return fmt.Sprintf("A4 %vHz", spinner)
+}
+
+func (m model) renderScala() string {
+	scl := zone.Mark(m.zonePrefix+sclInputId, m.sclInput.View())
+	kbm := zone.Mark(m.zonePrefix+kbmInputId, m.kbmInput.View())
+	return fmt.Sprintf("scl %v kbm %v", scl, kbm)
+}
+
+func (m model) Focused() bool {
+	return m.sclInput.Focused() || m.kbmInput.Focused()
+}
+
+// isKbmSelected returns true if the selected tuning has a keyboard mapping, which sets its own reference frequency
+func (m model) isKbmSelected() bool {
+	return m.hasKbm && m.tuningOptions.Value().Equals(m.scala)
+}
+
+// Tuning returns the selected tuning, tuned to the selected A4 frequency
+func (m model) Tuning() frequencies.Tuning {
+	if m.isKbmSelected() {
+		return m.tuningOptions.Value()
+	}
+
+	tuning, err := m.tuningOptions.Value().WithReference(m.referenceSpinner.Value())
+	if err != nil {
+		panic(err)
+	}
+	return tuning
+}
@@ -0,0 +1,139 @@
+package unison
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+
+	"github.com/HuBeZa/synth/models"
+	"github.com/HuBeZa/synth/models/base/slider"
+	"github.com/HuBeZa/synth/streamers"
+)
+
+const (
+	detuneSliderStep  = 5
+	spreadSliderRatio = 10
+
+	voicesSliderId = "voicesSlider"
+	detuneSliderId = "detuneSlider"
+	spreadSliderId = "spreadSlider"
+)
+
+var (
+	marginRight = lipgloss.NewStyle().MarginRight(2)
+	labelStyle  = lipgloss.NewStyle().Width(6)
+)
+
+type Model interface {
+	tea.Model
+	Voices() int
+	Detune() float64
+	Spread() float64
+}
+
+type model struct {
+	voicesSlider slider.Model
+	detuneSlider slider.Model
+	spreadSlider slider.Model
+	zonePrefix   string
+	zoneHandlers models.ZoneHandlers[model]
+}
+
+func New() Model {
+	m := model{}
+	m.voicesSlider, _ = slider.New(1, streamers.MaxUnisonVoices, 1, 1)
+	m.detuneSlider, _ = slider.New(0, 50, detuneSliderStep, 15)
+	m.spreadSlider, _ = slider.New(0, spreadSliderRatio, 1, spreadSliderRatio/2, spreadSliderRatio/2)
+
+	m.zonePrefix = zone.NewPrefix()
+	m.zoneHandlers = models.ZoneHandlers[model]{
+		m.zonePrefix + voicesSliderId: voicesSliderHandler,
+		m.zonePrefix + detuneSliderId: detuneSliderHandler,
+		m.zonePrefix + spreadSliderId: spreadSliderHandler,
+	}
+
+	return m
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		return m.zoneHandlers.Handle(m, msg)
+	}
+	return m, nil
+}
+
+func voicesSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.voicesSlider.Update(msg)
+	m.voicesSlider = sliderModel.(slider.Model)
+	return m, cmd
+}
+
+func detuneSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.detuneSlider.Update(msg)
+	m.detuneSlider = sliderModel.(slider.Model)
+	return m, cmd
+}
+
+func spreadSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.spreadSlider.Update(msg)
+	m.spreadSlider = sliderModel.(slider.Model)
+	return m, cmd
+}
+
+func (m model) View() string {
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		marginRight.Render(m.renderLabel()),
+		lipgloss.JoinVertical(lipgloss.Left,
+			lipgloss.JoinHorizontal(lipgloss.Top,
+				marginRight.Render(m.renderVoices()),
+				m.renderSpread(),
+			),
+			m.renderDetune(),
+		),
+	)
+}
+
+func (m model) renderLabel() string {
+	label := "unison"
+	if m.Voices() > 1 {
+		label = models.SelectedStyle().Render(label)
+	}
+	return label
+}
+
+func (m model) renderVoices() string {
+	slider := zone.Mark(m.zonePrefix+voicesSliderId, m.voicesSlider.View())
+	return fmt.Sprintf("%v %v", slider, m.Voices())
+}
+
+func (m model) renderDetune() string {
+	label := labelStyle.Render("detune")
+	slider := zone.Mark(m.zonePrefix+detuneSliderId, m.detuneSlider.View())
+	return fmt.Sprintf("%v %v ±%v¢", label, slider, m.Detune())
+}
+
+func (m model) renderSpread() string {
+	label := labelStyle.Render("spread")
+	slider := zone.Mark(m.zonePrefix+spreadSliderId, m.spreadSlider.View())
+	return fmt.Sprintf("%v %v %v", label, slider, m.Spread())
+}
+
+func (m model) Voices() int {
+	return m.voicesSlider.Value()
+}
+
+// Detune returns the detune of the outermost voices, in cents
+func (m model) Detune() float64 {
+	return float64(m.detuneSlider.Value())
+}
+
+func (m model) Spread() float64 {
+	return float64(m.spreadSlider.Value()) / float64(spreadSliderRatio)
+}
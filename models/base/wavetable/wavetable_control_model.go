@@ -0,0 +1,282 @@
+package wavetable
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+
+	"github.com/HuBeZa/synth/models"
+	"github.com/HuBeZa/synth/models/base/pathinput"
+	"github.com/HuBeZa/synth/models/base/slider"
+	"github.com/HuBeZa/synth/models/base/spinner"
+	"github.com/HuBeZa/synth/streamers"
+)
+
+const (
+	positionSliderRatio = 20
+	depthSliderRatio    = 10
+	amountSliderRatio   = 10
+	pathInputWidth      = 32
+	previewWidth        = 48
+	previewRows         = 4
+
+	// bubblezone ids:
+	pathInputId      = "pathInput"
+	positionSliderId = "positionSlider"
+	rateSpinnerId    = "rateSpinner"
+	depthSliderId    = "depthSlider"
+	amountSliderId   = "amountSlider"
+	timeSpinnerId    = "timeSpinner"
+)
+
+var (
+	rateValues = []rate{0, 0.1, 0.2, 0.5, 1, 2, 3, 5, 8}
+	timeValues = []time.Duration{0, 100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+		1 * time.Second, 2 * time.Second, 4 * time.Second}
+
+	// the preview is drawn from the bottom row up, with 3 levels per row (see waveform unicode art in streamers/waveform.go)
+	previewLevels = []string{"_", "-", "‾"}
+	previewStyle  = models.ForegroundColor("#87afff")
+	errorStyle    = models.ForegroundColor("#DF0000")
+)
+
+// rate is the position LFO rate, in Hz
+type rate float64
+
+func (r rate) String() string {
+	if r == 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%vHz", float64(r))
+}
+
+type Model interface {
+	tea.Model
+	Focused() bool
+	Bank() *streamers.WavetableBank
+	Position() streamers.WavetablePosition
+}
+
+type model struct {
+	pathInput      pathinput.Model
+	positionSlider slider.Model
+	rateSpinner    spinner.Model[rate]
+	depthSlider    slider.Model
+	amountSlider   slider.Model
+	timeSpinner    spinner.Model[time.Duration]
+	bank           *streamers.WavetableBank
+	loadErr        error
+	zonePrefix     string
+	zoneHandlers   models.ZoneHandlers[model]
+}
+
+func New() Model {
+	m := model{}
+	m.pathInput = pathinput.New("built-in (click to load WAV)", pathInputWidth)
+	m.positionSlider, _ = slider.New(0, positionSliderRatio, 1, 0, positionSliderRatio/2)
+	m.rateSpinner = spinner.New(rateValues, false)
+	m.depthSlider, _ = slider.New(0, depthSliderRatio, 1, depthSliderRatio/2)
+	m.amountSlider, _ = slider.New(-amountSliderRatio, amountSliderRatio, 1, 0, 0)
+	m.timeSpinner = spinner.New(timeValues, false).SetValue(3)
+	m.bank = streamers.BuiltinWavetableBank()
+	m.zonePrefix = zone.NewPrefix()
+	m.zoneHandlers = models.ZoneHandlers[model]{
+		m.zonePrefix + pathInputId:      pathInputHandler,
+		m.zonePrefix + positionSliderId: positionSliderHandler,
+		m.zonePrefix + rateSpinnerId:    rateSpinnerHandler,
+		m.zonePrefix + depthSliderId:    depthSliderHandler,
+		m.zonePrefix + amountSliderId:   amountSliderHandler,
+		m.zonePrefix + timeSpinnerId:    timeSpinnerHandler,
+	}
+
+	return m
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		return m.zoneHandlers.Handle(m, msg)
+	case tea.KeyMsg:
+		if !m.pathInput.Focused() {
+			return m, nil
+		}
+
+		inputModel, cmd := m.pathInput.Update(msg)
+		m.pathInput = inputModel.(pathinput.Model)
+		if !m.pathInput.Focused() {
+			m.loadBank()
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+func pathInputHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	inputModel, cmd := m.pathInput.Update(msg)
+	m.pathInput = inputModel.(pathinput.Model)
+	return m, cmd
+}
+
+func positionSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.positionSlider.Update(msg)
+	m.positionSlider = sliderModel.(slider.Model)
+	return m, cmd
+}
+
+func rateSpinnerHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	spinnerModel, cmd := m.rateSpinner.Update(msg)
+	m.rateSpinner = spinnerModel.(spinner.Model[rate])
+	return m, cmd
+}
+
+func depthSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.depthSlider.Update(msg)
+	m.depthSlider = sliderModel.(slider.Model)
+	return m, cmd
+}
+
+func amountSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.amountSlider.Update(msg)
+	m.amountSlider = sliderModel.(slider.Model)
+	return m, cmd
+}
+
+func timeSpinnerHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	spinnerModel, cmd := m.timeSpinner.Update(msg)
+	m.timeSpinner = spinnerModel.(spinner.Model[time.Duration])
+	return m, cmd
+}
+
+// loadBank loads the submitted WAV file. An empty path loads the built-in bank.
+func (m *model) loadBank() {
+	path := strings.TrimSpace(m.pathInput.Value())
+	if path == "" {
+		m.bank, m.loadErr = streamers.BuiltinWavetableBank(), nil
+		return
+	}
+
+	bank, err := streamers.LoadWavetableBank(path, streamers.DefaultWavetableFrameSize)
+	if err != nil {
+		m.loadErr = err
+		return
+	}
+	m.bank, m.loadErr = bank, nil
+}
+
+func (m model) View() string {
+	views := []string{
+		m.renderBank(),
+		m.renderPosition(),
+		m.renderLFO(),
+		m.renderEnvelope(),
+		m.renderPreview(),
+	}
+	if m.loadErr != nil {
+		views = append(views, errorStyle.Width(models.ColumnWidth).Render(m.loadErr.Error()))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, views...)
+}
+
+func (m model) renderBank() string {
+	label := models.LabelStyle().Render("table")
+	input := zone.Mark(m.zonePrefix+pathInputId, m.pathInput.View())
+	return fmt.Sprintf("%v%v %v frames", label, input, m.bank.Frames())
+}
+
+func (m model) renderPosition() string {
+	label := models.LabelStyle().Render("pos")
+	slider := zone.Mark(m.zonePrefix+positionSliderId, m.positionSlider.View())
+	return fmt.Sprintf("%v%v %v", label, slider, m.position())
+}
+
+func (m model) renderLFO() string {
+	label := models.LabelStyle().Render("lfo")
+	spinner := zone.Mark(m.zonePrefix+rateSpinnerId, m.rateSpinner.View())
+	depthLabel := models.LabelStyle().MarginLeft(2).Render("depth")
+	slider := zone.Mark(m.zonePrefix+depthSliderId, m.depthSlider.View())
+	return fmt.Sprintf("%v%v%v%v ±%v", label, spinner, depthLabel, slider, m.depth())
+}
+
+func (m model) renderEnvelope() string {
+	label := models.LabelStyle().Render("env")
+	slider := zone.Mark(m.zonePrefix+amountSliderId, m.amountSlider.View())
+	spinner := zone.Mark(m.zonePrefix+timeSpinnerId, m.timeSpinner.View())
+	return fmt.Sprintf("%v%v %+.1f %v", label, slider, m.amount(), spinner)
+}
+
+// renderPreview draws the frame at the current position (without modulation)
+func (m model) renderPreview() string {
+	frame := m.bank.Frame(m.position(), previewWidth)
+	levels := previewRows * len(previewLevels)
+
+	rows := make([][]string, previewRows)
+	for i := range rows {
+		rows[i] = make([]string, previewWidth)
+		for j := range rows[i] {
+			rows[i][j] = " "
+		}
+	}
+
+	prevRow := -1
+	for col, v := range frame {
+		v = min(max(v, -1), 1)
+		level := int((v + 1) / 2 * float64(levels-1))
+		row := previewRows - 1 - level/len(previewLevels)
+		rows[row][col] = previewLevels[level%len(previewLevels)]
+
+		// connect jumps between rows with vertical bars
+		if prevRow != -1 {
+			for r := min(row, prevRow) + 1; r < max(row, prevRow); r++ {
+				rows[r][col] = "│"
+			}
+		}
+		prevRow = row
+	}
+
+	lines := make([]string, previewRows)
+	for i, row := range rows {
+		lines[i] = models.LabelStyle().Render("") + previewStyle.Render(strings.Join(row, ""))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m model) Focused() bool {
+	return m.pathInput.Focused()
+}
+
+func (m model) Bank() *streamers.WavetableBank {
+	return m.bank
+}
+
+func (m model) position() float64 {
+	return float64(m.positionSlider.Value()) / positionSliderRatio
+}
+
+func (m model) depth() float64 {
+	return float64(m.depthSlider.Value()) / depthSliderRatio
+}
+
+func (m model) amount() float64 {
+	return float64(m.amountSlider.Value()) / amountSliderRatio
+}
+
+func (m model) Position() streamers.WavetablePosition {
+	position := streamers.WavetablePosition{
+		Position:       m.position(),
+		EnvelopeAmount: m.amount(),
+		EnvelopeTime:   m.timeSpinner.Value(),
+	}
+	if rate := m.rateSpinner.Value(); rate > 0 {
+		position.LFORate = float64(rate)
+		position.LFODepth = m.depth()
+	}
+	return position
+}
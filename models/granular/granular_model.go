@@ -0,0 +1,431 @@
+package granular
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gopxl/beep/v2"
+	zone "github.com/lrstanley/bubblezone"
+
+	"github.com/HuBeZa/synth/models"
+	"github.com/HuBeZa/synth/models/base/options"
+	"github.com/HuBeZa/synth/models/base/pathinput"
+	"github.com/HuBeZa/synth/models/base/scrubber"
+	"github.com/HuBeZa/synth/models/base/slider"
+	"github.com/HuBeZa/synth/models/base/spinner"
+	"github.com/HuBeZa/synth/streamers"
+	"github.com/HuBeZa/synth/streamers/composers"
+)
+
+const (
+	jitterSliderRatio = 10
+	pathInputWidth    = 32
+	scrubberWidth     = 44
+
+	// bubblezone ids:
+	upButtonId       = "upButton"
+	downButtonId     = "downButton"
+	closeButtonId    = "closeButton"
+	playStopButtonId = "playStopButton"
+	sourceOptionsId  = "sourceOptions"
+	pathInputId      = "pathInput"
+	scrubberId       = "scrubber"
+	sizeSpinnerId    = "sizeSpinner"
+	densitySpinnerId = "densitySpinner"
+	jitterSliderId   = "jitterSlider"
+	pitchSpinnerId   = "pitchSpinner"
+	windowOptionsId  = "windowOptions"
+)
+
+var (
+	labelStyle    = lipgloss.NewStyle().Width(8)
+	errorStyle    = models.ForegroundColor("#DF0000")
+	sizeValues    = []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond, 50 * time.Millisecond, 80 * time.Millisecond, 100 * time.Millisecond, 150 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 500 * time.Millisecond}
+	densityValues = []int{1, 2, 5, 10, 20, 30, 50, 80, 100}
+	pitchValues   = []int{0, 5, 10, 25, 50, 100, 200, 500, 1200}
+)
+
+// input is the grains source: a rack streamer, or the loaded file if Model is nil
+type input models.RackInput
+
+func (i input) Equals(other input) bool {
+	return models.RackInput(i).Equals(models.RackInput(other))
+}
+
+func (i input) String() string {
+	if i.Model == nil {
+		return "file"
+	}
+	return models.RackInput(i).String()
+}
+
+var fileInput = input{Index: -1}
+
+type model struct {
+	sourceOptions  options.Model[input]
+	pathInput      pathinput.Model
+	scrubber       scrubber.Model
+	sizeSpinner    spinner.Model[time.Duration]
+	densitySpinner spinner.Model[int]
+	jitterSlider   slider.Model
+	pitchSpinner   spinner.Model[int]
+	windowOptions  options.Model[composers.TransitionType]
+	sample         *streamers.Sample
+	loadErr        error
+	streamer       streamers.Granular
+	zonePrefix     string
+	zoneHandlers   models.ZoneHandlers[model]
+}
+
+func New(sr beep.SampleRate) models.StreamerModel {
+	defaults := streamers.DefaultGrainParams()
+
+	m := model{}
+	m.sourceOptions = options.New([]input{fileInput}, false)
+	m.pathInput = pathinput.New("built-in (click to load a file)", pathInputWidth)
+	m.scrubber = scrubber.New(scrubberWidth).SetValue(defaults.Position)
+	m.sizeSpinner = spinner.New(sizeValues, false).SetValue(slices.Index(sizeValues, defaults.Size))
+	m.densitySpinner = spinner.New(densityValues, false).SetValue(slices.Index(densityValues, int(defaults.Density)))
+	m.jitterSlider, _ = slider.New(0, jitterSliderRatio, 1, 0)
+	m.pitchSpinner = spinner.New(pitchValues, false)
+	m.windowOptions = options.New(composers.TransitionTypes(), false).SetValue(defaults.Window)
+	m.zonePrefix = zone.NewPrefix()
+	m.zoneHandlers = models.ZoneHandlers[model]{
+		m.zonePrefix + upButtonId:       upButtonHandler,
+		m.zonePrefix + downButtonId:     downButtonHandler,
+		m.zonePrefix + closeButtonId:    closeButtonHandler,
+		m.zonePrefix + playStopButtonId: playStopButtonHandler,
+		m.zonePrefix + sourceOptionsId:  sourceOptionsHandler,
+		m.zonePrefix + pathInputId:      pathInputHandler,
+		m.zonePrefix + scrubberId:       scrubberHandler,
+		m.zonePrefix + sizeSpinnerId:    sizeSpinnerHandler,
+		m.zonePrefix + densitySpinnerId: densitySpinnerHandler,
+		m.zonePrefix + jitterSliderId:   jitterSliderHandler,
+		m.zonePrefix + pitchSpinnerId:   pitchSpinnerHandler,
+		m.zonePrefix + windowOptionsId:  windowOptionsHandler,
+	}
+
+	var err error
+	m.streamer, err = streamers.NewGranular(sr, m.currentParams())
+	if err != nil {
+		panic(err)
+	}
+	m.setSample(streamers.BuiltinSample())
+
+	return m
+}
+
+func (m model) Equals(other tea.Model) bool {
+	if other, ok := other.(model); ok {
+		return m.zonePrefix == other.zonePrefix
+	}
+	return false
+}
+
+func (m model) Streamer() beep.Streamer {
+	return m.streamer
+}
+
+func (m model) Inputs() []models.StreamerModel {
+	if source := m.sourceOptions.Value().Model; source != nil {
+		return []models.StreamerModel{source}
+	}
+	return nil
+}
+
+func (m model) Focused() bool {
+	return m.pathInput.Focused()
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		return m.zoneHandlers.Handle(m, msg)
+	case tea.KeyMsg:
+		if !m.pathInput.Focused() {
+			return m, nil
+		}
+
+		inputModel, cmd := m.pathInput.Update(msg)
+		m.pathInput = inputModel.(pathinput.Model)
+		if !m.pathInput.Focused() {
+			m.loadSample()
+		}
+		return m, cmd
+	case models.RackChangedMsg:
+		return m.updateInputs(msg.Streamers)
+	}
+	return m, nil
+}
+
+// updateInputs refreshes the source options with the current rack streamers
+func (m model) updateInputs(rack []models.StreamerModel) (tea.Model, tea.Cmd) {
+	inputs := []input{fileInput}
+	for _, rackInput := range models.RackInputs(m, rack) {
+		inputs = append(inputs, input(rackInput))
+	}
+
+	origSource := m.sourceOptions.Value()
+	m.sourceOptions = options.New(inputs, false).SetValue(origSource)
+
+	if m.sourceOptions.Value().Equals(origSource) {
+		return m, nil
+	}
+
+	// the source was removed from the rack
+	m.updateStreamerInput()
+	return m, models.RoutingChangedFunc(m)
+}
+
+func upButtonHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Action == tea.MouseActionRelease && msg.Button == tea.MouseButtonLeft {
+		return m, models.StreamerUpFunc(m)
+	}
+	return m, nil
+}
+
+func downButtonHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Action == tea.MouseActionRelease && msg.Button == tea.MouseButtonLeft {
+		return m, models.StreamerDownFunc(m)
+	}
+	return m, nil
+}
+
+func closeButtonHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Action == tea.MouseActionRelease && msg.Button == tea.MouseButtonLeft {
+		return m, models.RemoveStreamerFunc(m)
+	}
+	return m, nil
+}
+
+func playStopButtonHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Action == tea.MouseActionRelease && msg.Button == tea.MouseButtonLeft {
+		m.streamer.ToggleSilence()
+	}
+	return m, nil
+}
+
+func sourceOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	origSource := m.sourceOptions.Value()
+	optionsModel, cmd := m.sourceOptions.Update(msg)
+	m.sourceOptions = optionsModel.(options.Model[input])
+	if m.sourceOptions.Value().Equals(origSource) {
+		return m, cmd
+	}
+
+	m.updateStreamerInput()
+	return m, tea.Batch(cmd, models.RoutingChangedFunc(m))
+}
+
+func pathInputHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	inputModel, cmd := m.pathInput.Update(msg)
+	m.pathInput = inputModel.(pathinput.Model)
+	return m, cmd
+}
+
+func scrubberHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	scrubberModel, cmd := m.scrubber.Update(msg)
+	m.scrubber = scrubberModel.(scrubber.Model)
+	m.updateStreamerParams()
+	return m, cmd
+}
+
+func sizeSpinnerHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	spinnerModel, cmd := m.sizeSpinner.Update(msg)
+	m.sizeSpinner = spinnerModel.(spinner.Model[time.Duration])
+	m.updateStreamerParams()
+	return m, cmd
+}
+
+func densitySpinnerHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	spinnerModel, cmd := m.densitySpinner.Update(msg)
+	m.densitySpinner = spinnerModel.(spinner.Model[int])
+	m.updateStreamerParams()
+	return m, cmd
+}
+
+func jitterSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.jitterSlider.Update(msg)
+	m.jitterSlider = sliderModel.(slider.Model)
+	m.scrubber = m.scrubber.SetJitter(m.currentParams().PositionJitter)
+	m.updateStreamerParams()
+	return m, cmd
+}
+
+func pitchSpinnerHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	spinnerModel, cmd := m.pitchSpinner.Update(msg)
+	m.pitchSpinner = spinnerModel.(spinner.Model[int])
+	m.updateStreamerParams()
+	return m, cmd
+}
+
+func windowOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	optionsModel, cmd := m.windowOptions.Update(msg)
+	m.windowOptions = optionsModel.(options.Model[composers.TransitionType])
+	m.updateStreamerParams()
+	return m, cmd
+}
+
+// loadSample loads the submitted file. An empty path loads the built-in sample.
+func (m *model) loadSample() {
+	path := strings.TrimSpace(m.pathInput.Value())
+	if path == "" {
+		m.setSample(streamers.BuiltinSample())
+		m.loadErr = nil
+		return
+	}
+
+	sample, err := streamers.LoadSample(path)
+	if err != nil {
+		m.loadErr = err
+		return
+	}
+	m.setSample(sample)
+	m.loadErr = nil
+}
+
+// setSample sets the file source, and draws its overview in the scrubber
+func (m *model) setSample(sample *streamers.Sample) {
+	m.sample = sample
+	m.streamer.SetSample(sample)
+	m.updateOverview()
+}
+
+func (m *model) updateOverview() {
+	if m.sourceOptions.Value().Model != nil {
+		// the recorded buffer changes constantly, so there is no overview to draw
+		m.scrubber = m.scrubber.SetOverview(nil)
+		return
+	}
+
+	peaks := m.sample.Peaks(scrubberWidth)
+	if maxPeak := slices.Max(peaks); maxPeak > 0 {
+		for i := range peaks {
+			peaks[i] /= maxPeak
+		}
+	}
+	m.scrubber = m.scrubber.SetOverview(peaks)
+}
+
+func (m *model) updateStreamerInput() {
+	m.streamer.SetInput(inputStreamer(m.sourceOptions.Value()))
+	m.updateOverview()
+}
+
+func inputStreamer(in input) beep.Streamer {
+	if in.Model == nil {
+		return nil
+	}
+	return in.Model.Streamer()
+}
+
+func (m model) updateStreamerParams() {
+	if err := m.streamer.SetGrains(m.currentParams()); err != nil {
+		panic(err)
+	}
+}
+
+func (m model) currentParams() streamers.GrainParams {
+	return streamers.GrainParams{
+		Size:           m.sizeSpinner.Value(),
+		Density:        float64(m.densitySpinner.Value()),
+		Position:       m.scrubber.Value(),
+		PositionJitter: float64(m.jitterSlider.Value()) / jitterSliderRatio,
+		PitchJitter:    float64(m.pitchSpinner.Value()),
+		Window:         m.windowOptions.Value(),
+	}
+}
+
+func (m model) View() string {
+	views := []string{
+		m.renderHeader(models.ColumnWidth),
+		m.renderSourceOptions(),
+	}
+	if m.sourceOptions.Value().Model == nil {
+		views = append(views, m.renderPathInput())
+	}
+	if m.loadErr != nil {
+		views = append(views, errorStyle.Width(models.ColumnWidth).Render(m.loadErr.Error()))
+	}
+	views = append(views,
+		m.renderScrubber(),
+		m.renderSizeDensity(),
+		m.renderJitter(),
+		m.renderWindowOptions())
+	return lipgloss.JoinVertical(lipgloss.Left, views...)
+}
+
+func (m model) renderHeader(width int) string {
+	widthLeft := width * 9 / 10
+	widthRight := width - widthLeft
+
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		m.renderHeaderText(widthLeft),
+		m.renderHeaderButtons(widthRight))
+}
+
+func (m model) renderHeaderText(width int) string {
+	source := m.sourceOptions.Value().String()
+	if m.sourceOptions.Value().Model == nil {
+		source = m.sample.Name()
+	}
+	header := models.HeaderStyle().Render(fmt.Sprintf("granular %v", source))
+
+	playStopButton := models.PlayButton()
+	if m.streamer.IsSilenced() {
+		playStopButton = models.StopButton()
+	}
+
+	id := m.zonePrefix + playStopButtonId
+	view := zone.Mark(id, fmt.Sprintf("%v %v", playStopButton, header))
+	return lipgloss.NewStyle().Width(width).AlignHorizontal(lipgloss.Left).Render(view)
+}
+
+func (m model) renderHeaderButtons(width int) string {
+	upButton := zone.Mark(m.zonePrefix+upButtonId, models.UpButton())
+	downButton := zone.Mark(m.zonePrefix+downButtonId, models.DownButton())
+	closeButton := zone.Mark(m.zonePrefix+closeButtonId, models.CloseButton())
+	view := lipgloss.JoinHorizontal(lipgloss.Top, upButton, downButton, closeButton)
+
+	return lipgloss.NewStyle().Width(width).AlignHorizontal(lipgloss.Right).MarginRight(1).Render(view)
+}
+
+func (m model) renderSourceOptions() string {
+	id := m.zonePrefix + sourceOptionsId
+	return labelStyle.Render("source") + zone.Mark(id, m.sourceOptions.View())
+}
+
+func (m model) renderPathInput() string {
+	id := m.zonePrefix + pathInputId
+	return labelStyle.Render("file") + zone.Mark(id, m.pathInput.View())
+}
+
+func (m model) renderScrubber() string {
+	id := m.zonePrefix + scrubberId
+	return lipgloss.JoinHorizontal(lipgloss.Top, labelStyle.Render("pos"), zone.Mark(id, m.scrubber.View()))
+}
+
+func (m model) renderSizeDensity() string {
+	size := zone.Mark(m.zonePrefix+sizeSpinnerId, m.sizeSpinner.View())
+	density := zone.Mark(m.zonePrefix+densitySpinnerId, m.densitySpinner.View())
+	return fmt.Sprintf("%v%v  %v%v/s", labelStyle.Render("size"), size, labelStyle.Render("density"), density)
+}
+
+func (m model) renderJitter() string {
+	jitter := zone.Mark(m.zonePrefix+jitterSliderId, m.jitterSlider.View())
+	pitch := zone.Mark(m.zonePrefix+pitchSpinnerId, m.pitchSpinner.View())
+	return fmt.Sprintf("%v%v  %v%v¢", labelStyle.Render("jitter"), jitter, labelStyle.Render("pitch"), pitch)
+}
+
+func (m model) renderWindowOptions() string {
+	id := m.zonePrefix + windowOptionsId
+	return labelStyle.Render("window") + zone.Mark(id, m.windowOptions.View())
+}
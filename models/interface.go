@@ -10,3 +10,17 @@ type StreamerModel interface {
 	Equals(other tea.Model) bool
 	Streamer() beep.Streamer
 }
+
+// RoutingModel is a StreamerModel that consumes the output of other rack streamers.
+// Its inputs should not be played directly by the speaker.
+type RoutingModel interface {
+	StreamerModel
+	Inputs() []StreamerModel
+}
+
+// FocusableModel is a StreamerModel with a text input.
+// While focused, it should receive all key presses, and no other model should.
+type FocusableModel interface {
+	StreamerModel
+	Focused() bool
+}
@@ -2,6 +2,7 @@ package keyboard
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -12,133 +13,256 @@ import (
 	zone "github.com/lrstanley/bubblezone"
 
 	"github.com/HuBeZa/synth/models"
+	"github.com/HuBeZa/synth/models/base/checkbox"
 	"github.com/HuBeZa/synth/models/base/chords"
 	"github.com/HuBeZa/synth/models/base/envelope"
+	"github.com/HuBeZa/synth/models/base/fm"
+	"github.com/HuBeZa/synth/models/base/glide"
 	"github.com/HuBeZa/synth/models/base/options"
 	"github.com/HuBeZa/synth/models/base/overtones"
+	"github.com/HuBeZa/synth/models/base/pathinput"
+	"github.com/HuBeZa/synth/models/base/pulse"
+	"github.com/HuBeZa/synth/models/base/sample"
+	"github.com/HuBeZa/synth/models/base/scale"
 	"github.com/HuBeZa/synth/models/base/slider"
 	"github.com/HuBeZa/synth/models/base/tremolo"
+	"github.com/HuBeZa/synth/models/base/tuning"
+	"github.com/HuBeZa/synth/models/base/unison"
+	"github.com/HuBeZa/synth/models/base/wavetable"
 	"github.com/HuBeZa/synth/streamers"
+	streamerchords "github.com/HuBeZa/synth/streamers/chords"
 	"github.com/HuBeZa/synth/streamers/frequencies"
+	"github.com/HuBeZa/synth/streamers/scales"
 )
 
 const (
-	// basic ascii keyboard - vertical bars & underscores only
-	asciiKeyboard = "" +
-		"_________________________________________\n" +
-		"|  | | | |  |  | | | | | |  |  | | | |  |\n" +
-		"|  | | | |  |  | | | | | |  |  | | | |  |\n" +
-		"|  |w| |e|  |  |t| |y| |u|  |  |o| |p|  |\n" +
-		"|  |_| |_|  |  |_| |_| |_|  |  |_| |_|  |\n" +
-		"|   |   |   |   |   |   |   |   |   |   |\n" +
-		"| a | s | d | f | g | h | j | k | l | ; |\n" +
-		"|___|___|___|___|___|___|___|___|___|___|\n"
-
-	// Unicode Box-drawing characters keyboard (see https://en.wikipedia.org/wiki/Box-drawing_characters)
-	keyboardTall = "" +
-		"╒══╤═╤═╤═╤══╤══╤═╤═╤═╤═╤═╤══╤══╤═╤═╤═╤══╕\n" +
-		"│  │ │ │ │  │  │ │ │ │ │ │  │  │ │ │ │  │\n" +
-		"│  │ │ │ │  │  │ │ │ │ │ │  │  │ │ │ │  │\n" +
-		"│  │w│ │e│  │  │t│ │y│ │u│  │  │o│ │p│  │\n" +
-		"│  └┬┘ └┬┘  │  └┬┘ └┬┘ └┬┘  │  └┬┘ └┬┘  │\n" +
-		"│   │   │   │   │   │   │   │   │   │   │\n" +
-		"│ a │ s │ d │ f │ g │ h │ j │ k │ l │ ; │\n" +
-		"└───┴───┴───┴───┴───┴───┴───┴───┴───┴───┘"
-
-	keyboard = "" +
-		"╒══╤═╤═╤═╤══╤══╤═╤═╤═╤═╤═╤══╤══╤═╤═╤═╤══╕\n" +
-		"│  │w│ │e│  │  │t│ │y│ │u│  │  │o│ │p│  │\n" +
-		"│  └┬┘ └┬┘  │  └┬┘ └┬┘ └┬┘  │  └┬┘ └┬┘  │\n" +
-		"│ a │ s │ d │ f │ g │ h │ j │ k │ l │ ; │\n" +
-		"└───┴───┴───┴───┴───┴───┴───┴───┴───┴───┘"
-
-	panSliderRatio  = 10
-	gainSliderRatio = 5
+	panSliderRatio   = 10
+	gainSliderRatio  = 5
+	maxTranspose     = 12
+	keyMapInputWidth = 24
+	// releases a held key whose key release event was lost, when no repeated key presses arrive for that long
+	keyReleaseFallbackTimeout = 2 * time.Second
+	// the smoothing slider spans 0 to 100ms in 5ms steps
+	smoothingSliderStep  = 5 * time.Millisecond
+	smoothingSliderSteps = 20
 
 	// bubblezone ids:
-	upButtonId        = "upButton"
-	downButtonId      = "downButton"
-	closeButtonId     = "closeButton"
-	playStopButtonId  = "playStopButton"
-	waveformOptionsId = "waveformOptions"
-	octaveSliderId    = "octaveSlider"
-	panSliderId       = "panSlider"
-	gainSliderId      = "gainSlider"
-	chordsCtrlId      = "chordsCtrl"
-	overtonesCtrlId   = "overtonesCtrl"
-	tremoloCtrlId     = "tremoloCtrl"
-	envelopeCtrlId    = "envelopeCtrl"
+	upButtonId            = "upButton"
+	downButtonId          = "downButton"
+	closeButtonId         = "closeButton"
+	playStopButtonId      = "playStopButton"
+	latchButtonId         = "latchButton"
+	waveformOptionsId     = "waveformOptions"
+	octaveSliderId        = "octaveSlider"
+	panSliderId           = "panSlider"
+	gainSliderId          = "gainSlider"
+	smoothingSliderId     = "smoothingSlider"
+	chordsCtrlId          = "chordsCtrl"
+	overtonesCtrlId       = "overtonesCtrl"
+	unisonCtrlId          = "unisonCtrl"
+	tremoloCtrlId         = "tremoloCtrl"
+	envelopeCtrlId        = "envelopeCtrl"
+	fmCtrlId              = "fmCtrl"
+	bandLimitedCheckboxId = "bandLimitedCheckbox"
+	glideCtrlId           = "glideCtrl"
+	pulseCtrlId           = "pulseCtrl"
+	curvatureSliderId     = "curvatureSlider"
+	wavetableCtrlId       = "wavetableCtrl"
+	sampleCtrlId          = "sampleCtrl"
+	tuningCtrlId          = "tuningCtrl"
+	keyMapOptionsId       = "keyMapOptions"
+	keyMapInputId         = "keyMapInput"
+	scaleCtrlId           = "scaleCtrl"
 )
 
 var (
-	octaveToKeys    = initOctaveToKeys()
 	currKeyStyle    = lipgloss.NewStyle().Reverse(true)
+	dimmedKeyStyle  = models.ForegroundColor("#585858")
 	marginLeftStyle = lipgloss.NewStyle().MarginLeft(1)
+	errorStyle      = models.ForegroundColor("#DF0000")
 )
 
-func initOctaveToKeys() map[int]map[string]frequencies.Frequency {
+// newOctaveToKeys maps the keys to consecutive notes of the tuning, starting at the first note of each octave,
+// shifted by transposeSteps steps of the tuning.
+// When locked to a key, the keys are mapped to consecutive notes of the key, starting at the root of each octave,
+// shifted by transposeNotes notes of the key (e.g. +1 starts at the 2nd note of the key).
+func newOctaveToKeys(tuning frequencies.Tuning, keys []string, key scales.Key, isLocked bool, transposeSteps, transposeNotes int) map[int]map[string]frequencies.Frequency {
+	divisions := tuning.Divisions()
 	octavesMap := make(map[int]map[string]frequencies.Frequency, 11)
-	keys := []string{"a", "w", "s", "e", "d", "f", "t", "g", "y", "h", "u", "j", "k", "o", "l", "p", ";"}
-
-	baseLow := frequencies.C0()
-	baseHigh := baseLow.ShiftSemitone(len(keys) - 1)
-
 	for octaveId := -1; octaveId <= 9; octaveId++ {
 		octavesMap[octaveId] = make(map[string]frequencies.Frequency, len(keys))
-		octaveFrequencies := frequencies.GetRange(baseLow.ShiftOctave(octaveId), baseHigh.ShiftOctave(octaveId))
-		for i, key := range keys {
-			octavesMap[octaveId][key] = octaveFrequencies[i]
+		// octave -1 starts at step 0
+		baseStep := (octaveId + 1) * divisions
+		if !isLocked {
+			for i, key := range keys {
+				octavesMap[octaveId][key] = tuning.Note(baseStep + transposeSteps + i)
+			}
+			continue
+		}
+
+		step := baseStep + key.RootStep(divisions)
+		for range abs(transposeNotes) {
+			if transposeNotes > 0 {
+				step = nextKeyStep(key, step+1, 1, divisions)
+			} else {
+				step = nextKeyStep(key, step-1, -1, divisions)
+			}
+		}
+		for _, k := range keys {
+			step = nextKeyStep(key, step, 1, divisions)
+			octavesMap[octaveId][k] = tuning.Note(step)
+			step++
 		}
 	}
 	return octavesMap
 }
 
+// nextKeyStep returns the first step of the key from step in the given direction (1 or -1), including step itself.
+// A key may have no steps at all in tunings with few divisions, in which case step is returned.
+func nextKeyStep(key scales.Key, step, direction, divisions int) int {
+	for i := 0; i < divisions; i++ {
+		if next := step + i*direction; key.ContainsStep(next, divisions) {
+			return next
+		}
+	}
+	return step
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// newDimmedKeys returns the keys that are mapped to notes out of the key, after shifting them by transposeSteps
+func newDimmedKeys(tuning frequencies.Tuning, keys []string, key scales.Key, transposeSteps int) map[string]bool {
+	dimmedKeys := make(map[string]bool, len(keys))
+	for i, k := range keys {
+		if !key.ContainsStep(i+transposeSteps, tuning.Divisions()) {
+			dimmedKeys[k] = true
+		}
+	}
+	return dimmedKeys
+}
+
 type model struct {
-	waveformOptions options.Model[streamers.Waveform]
-	octaveSlider    slider.Model
-	panSlider       slider.Model
-	gainSlider      slider.Model
-	chordsCtrl      chords.Model
-	overtonesCtrl   overtones.Model
-	tremoloCtrl     tremolo.Model
-	envelopeCtrl    envelope.Model
-
-	isSilenced    bool
+	waveformOptions     options.Model[streamers.Waveform]
+	bandLimitedCheckbox checkbox.Model
+	octaveSlider        slider.Model
+	panSlider           slider.Model
+	gainSlider          slider.Model
+	smoothingSlider     slider.Model
+	chordsCtrl          chords.Model
+	overtonesCtrl       overtones.Model
+	unisonCtrl          unison.Model
+	tremoloCtrl         tremolo.Model
+	envelopeCtrl        envelope.Model
+	fmCtrl              fm.Model
+	glideCtrl           glide.Model
+	pulseCtrl           pulse.Model
+	curvatureSlider     slider.Model
+	wavetableCtrl       wavetable.Model
+	sampleCtrl          sample.Model
+	tuningCtrl          tuning.Model
+	scaleCtrl           scale.Model
+	keyMapOptions       options.Model[KeyMap]
+	keyMapInput         pathinput.Model
+	keyMapErr           error
+
+	// a sampler plays the sample of sampleCtrl instead of a waveform
+	isSampler  bool
+	isSilenced bool
+	// with key release events, notes are released on key release instead of by the key press timer
+	hasKeyRelease bool
+	octaveToKeys  map[int]map[string]frequencies.Frequency
+	keyboardArt   string
+	// semitones added to the notes of the keys
+	transpose int
+	// keys out of the selected key, when it is highlighted
+	dimmedKeys    map[string]bool
 	keyPressTimer timer.Model
-	currKey       string
-	currFreq      frequencies.Frequency
-	streamer      streamers.DynamicStreamer
-	zonePrefix    string
-	zoneHandlers  models.ZoneHandlers[model]
+	// latch keeps the last note sounding until the next key is pressed
+	isLatched bool
+	// the sustain pedal defers the note release while it is held
+	isSustained       bool
+	isReleaseDeferred bool
+	sustainTimer      timer.Model
+	currKey           string
+	currFreq          frequencies.Frequency
+	streamer          streamers.DynamicStreamer
+	zonePrefix        string
+	zoneHandlers      models.ZoneHandlers[model]
 }
 
 func New(sr beep.SampleRate) models.StreamerModel {
+	return newModel(sr)
+}
+
+// NewSampler returns a keyboard that plays a recorded sample, pitch shifted to the pressed keys
+func NewSampler(sr beep.SampleRate) models.StreamerModel {
+	m := newModel(sr)
+	m.isSampler = true
+	m.updateSample()
+	return m
+}
+
+func newModel(sr beep.SampleRate) model {
 	m := model{}
-	m.waveformOptions = options.New(streamers.AllWaveforms(), false)
+	m.waveformOptions = options.New(streamers.AllWaveforms(), false).SetWidth(models.ColumnWidth)
 	m.octaveSlider, _ = slider.New(-1, 9, 1, 3, 4)
 	m.panSlider, _ = slider.New(-panSliderRatio, panSliderRatio, 1, 0, 0)
 	m.gainSlider, _ = slider.New(0, gainSliderRatio*4, 1, gainSliderRatio, gainSliderRatio, gainSliderRatio*2, gainSliderRatio*3)
+	m.smoothingSlider, _ = slider.New(0, smoothingSliderSteps, 1, int(streamers.DefaultSmoothingTime/smoothingSliderStep))
 	m.chordsCtrl = chords.New()
 	m.overtonesCtrl = overtones.New()
+	m.unisonCtrl = unison.New()
 	m.tremoloCtrl = tremolo.New()
 	m.envelopeCtrl = envelope.New()
+	m.fmCtrl = fm.New()
+	m.bandLimitedCheckbox = checkbox.New("band-limited", true)
+	m.glideCtrl = glide.New()
+	m.pulseCtrl = pulse.New()
+	m.wavetableCtrl = wavetable.New()
+	m.sampleCtrl = sample.New()
+	m.tuningCtrl = tuning.New()
+	m.scaleCtrl = scale.New()
+	m.keyMapOptions = options.New(KeyMaps(), false)
+	m.keyMapInput = pathinput.New("click to load key map", keyMapInputWidth)
+	m.updateKeys()
+	m.curvatureSlider, _ = slider.New(int(streamers.MinCurvature), int(streamers.MaxCurvature), 1, int(streamers.DefaultCurvature))
 	m.zonePrefix = zone.NewPrefix()
 	m.zoneHandlers = models.ZoneHandlers[model]{
-		m.zonePrefix + upButtonId:        upButtonHandler,
-		m.zonePrefix + downButtonId:      downButtonHandler,
-		m.zonePrefix + closeButtonId:     closeButtonHandler,
-		m.zonePrefix + playStopButtonId:  playStopButtonHandler,
-		m.zonePrefix + waveformOptionsId: waveformOptionsHandler,
-		m.zonePrefix + octaveSliderId:    octaveSliderHandler,
-		m.zonePrefix + panSliderId:       panSliderHandler,
-		m.zonePrefix + gainSliderId:      gainSliderHandler,
-		m.zonePrefix + chordsCtrlId:      chordsCtrlHandler,
-		m.zonePrefix + overtonesCtrlId:   overtonesCtrlHandler,
-		m.zonePrefix + tremoloCtrlId:     tremoloCtrlHandler,
-		m.zonePrefix + envelopeCtrlId:    envelopeCtrlHandler,
+		m.zonePrefix + upButtonId:            upButtonHandler,
+		m.zonePrefix + downButtonId:          downButtonHandler,
+		m.zonePrefix + closeButtonId:         closeButtonHandler,
+		m.zonePrefix + playStopButtonId:      playStopButtonHandler,
+		m.zonePrefix + latchButtonId:         latchButtonHandler,
+		m.zonePrefix + waveformOptionsId:     waveformOptionsHandler,
+		m.zonePrefix + bandLimitedCheckboxId: bandLimitedCheckboxHandler,
+		m.zonePrefix + octaveSliderId:        octaveSliderHandler,
+		m.zonePrefix + panSliderId:           panSliderHandler,
+		m.zonePrefix + gainSliderId:          gainSliderHandler,
+		m.zonePrefix + smoothingSliderId:     smoothingSliderHandler,
+		m.zonePrefix + chordsCtrlId:          chordsCtrlHandler,
+		m.zonePrefix + overtonesCtrlId:       overtonesCtrlHandler,
+		m.zonePrefix + unisonCtrlId:          unisonCtrlHandler,
+		m.zonePrefix + tremoloCtrlId:         tremoloCtrlHandler,
+		m.zonePrefix + envelopeCtrlId:        envelopeCtrlHandler,
+		m.zonePrefix + fmCtrlId:              fmCtrlHandler,
+		m.zonePrefix + glideCtrlId:           glideCtrlHandler,
+		m.zonePrefix + pulseCtrlId:           pulseCtrlHandler,
+		m.zonePrefix + curvatureSliderId:     curvatureSliderHandler,
+		m.zonePrefix + wavetableCtrlId:       wavetableCtrlHandler,
+		m.zonePrefix + sampleCtrlId:          sampleCtrlHandler,
+		m.zonePrefix + tuningCtrlId:          tuningCtrlHandler,
+		m.zonePrefix + scaleCtrlId:           scaleCtrlHandler,
+		m.zonePrefix + keyMapOptionsId:       keyMapOptionsHandler,
+		m.zonePrefix + keyMapInputId:         keyMapInputHandler,
 	}
 
 	m.streamer, _ = streamers.NewWaveformDynamicStreamer(sr, frequencies.Silence(), m.currentPan(), m.currentGain(), m.currentWaveform())
+	m.streamer.SetBandLimited(m.bandLimitedCheckbox.Value())
 	m.streamer.TriggerRelease()
 
 	return m
@@ -151,6 +275,17 @@ func (m model) Equals(other tea.Model) bool {
 	return false
 }
 
+// Focused returns true while the tuning, chords, key map, wavetable or sample file path is edited
+func (m model) Focused() bool {
+	if m.tuningCtrl.Focused() || m.chordsCtrl.Focused() || m.keyMapInput.Focused() {
+		return true
+	}
+	if m.isSampler {
+		return m.sampleCtrl.Focused()
+	}
+	return m.currentWaveform() == streamers.Wavetable && m.wavetableCtrl.Focused()
+}
+
 func (m model) Streamer() beep.Streamer {
 	return m.streamer
 }
@@ -164,31 +299,84 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.MouseMsg:
 		return m.zoneHandlers.Handle(m, msg)
 	case tea.KeyMsg:
-		switch key := msg.String(); key {
-		case "a", "w", "s", "e", "d", "f", "t", "g", "y", "h", "u", "j", "k", "o", "l", "p", ";":
-			keyPressTimeout := 40
-			if key != m.currKey {
-				freq := octaveToKeys[m.octaveSlider.Value()][key]
-				if !m.isSilenced {
-					m.streamer.SetFrequency(freq)
-					m.streamer.TriggerAttack()
+		switch {
+		case m.tuningCtrl.Focused():
+			return m.updateTuningCtrl(msg)
+		case m.chordsCtrl.Focused():
+			return m.updateChordsCtrl(msg)
+		case m.keyMapInput.Focused():
+			return m.updateKeyMapInput(msg)
+		case m.Focused() && m.isSampler:
+			return m.updateSampleCtrl(msg)
+		case m.Focused():
+			return m.updateWavetableCtrl(msg)
+		}
+
+		keyMap := m.keyMapOptions.Value()
+		key := msg.String()
+		// every octave maps all the note keys of the key map, so it is also the lookup of the note keys
+		freq, isNoteKey := m.octaveToKeys[m.octaveSlider.Value()][key]
+		switch {
+		case isNoteKey:
+			isNewPress := key != m.currKey
+			if isNewPress {
+				// keys left unmapped by the tuning's keyboard mapping are silent
+				if !m.isSilenced && freq.Frequency() > 0 {
+					m.playKey(freq)
 				}
 				m.currKey = key
 				m.currFreq = freq
-				keyPressTimeout = 280
 			}
 
-			m.keyPressTimer = timer.NewWithInterval(time.Duration(keyPressTimeout)*time.Millisecond, 10*time.Millisecond)
+			m.keyPressTimer = m.newReleaseTimer(isNewPress)
 			return m, m.keyPressTimer.Init()
+		case key == keyMap.OctaveDown || key == keyMap.OctaveUp:
+			diff := 1
+			if key == keyMap.OctaveDown {
+				diff = -1
+			}
+			if octaveSlider, err := m.octaveSlider.SetValue(m.octaveSlider.Value() + diff); err == nil {
+				m.octaveSlider = octaveSlider
+			}
+		case key == keyMap.TransposeDown:
+			m.transpose = max(m.transpose-1, -maxTranspose)
+			m.updateKeys()
+		case key == keyMap.TransposeUp:
+			m.transpose = min(m.transpose+1, maxTranspose)
+			m.updateKeys()
+		case key == keyMap.Sustain:
+			isNewPress := !m.isSustained
+			m.isSustained = true
+			m.sustainTimer = m.newReleaseTimer(isNewPress)
+			return m, m.sustainTimer.Init()
+		case key == keyMap.Latch:
+			m.toggleLatch()
 		}
-	case timer.TickMsg:
-		if msg.Timeout {
-			m.currKey = ""
-			m.streamer.TriggerRelease()
+	case models.KeyReleaseSupportedMsg:
+		m.hasKeyRelease = true
+	case models.KeyReleaseMsg:
+		switch {
+		case !m.hasKeyRelease:
+		case msg.Key == m.keyMapOptions.Value().Sustain:
+			m.releaseSustain()
+		case msg.Key == m.currKey:
+			m.releaseKey()
 		}
-
+	case timer.TickMsg:
 		var cmd tea.Cmd
-		m.keyPressTimer, cmd = m.keyPressTimer.Update(msg)
+		switch msg.ID {
+		case m.keyPressTimer.ID():
+			// the key may have been released already by its key release event
+			if msg.Timeout && m.currKey != "" {
+				m.releaseKey()
+			}
+			m.keyPressTimer, cmd = m.keyPressTimer.Update(msg)
+		case m.sustainTimer.ID():
+			if msg.Timeout && m.isSustained {
+				m.releaseSustain()
+			}
+			m.sustainTimer, cmd = m.sustainTimer.Update(msg)
+		}
 		return m, cmd
 		// case timer.TimeoutMsg:	// already handled on TickMsg
 		// case timer.StartStopMsg:	// required only if Start/Stop/Toggle is called
@@ -196,6 +384,61 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// newReleaseTimer returns a timer that releases the pressed key (or the sustain pedal) when it times out.
+// Without key release events, the key is released when no repeated key presses arrive.
+// With key release events, it releases the key only if its release event is lost (e.g. when the terminal loses focus),
+// so its timeout is longer than the key repeat delay.
+func (m model) newReleaseTimer(isNewPress bool) timer.Model {
+	switch {
+	case m.hasKeyRelease:
+		return timer.NewWithInterval(keyReleaseFallbackTimeout, 100*time.Millisecond)
+	case isNewPress:
+		return timer.NewWithInterval(280*time.Millisecond, 10*time.Millisecond)
+	default:
+		return timer.NewWithInterval(40*time.Millisecond, 10*time.Millisecond)
+	}
+}
+
+func (m *model) releaseKey() {
+	m.currKey = ""
+	switch {
+	case m.isLatched:
+		// the note is released by the next key press
+	case m.isSustained:
+		m.isReleaseDeferred = true
+	default:
+		m.streamer.TriggerRelease()
+	}
+}
+
+// releaseSustain releases the note deferred by the sustain pedal, unless its key is still pressed
+func (m *model) releaseSustain() {
+	m.isSustained = false
+	if m.isReleaseDeferred && m.currKey == "" && !m.isLatched {
+		m.streamer.TriggerRelease()
+	}
+	m.isReleaseDeferred = false
+}
+
+// toggleLatch turns latch on or off. Turning it off releases the latched note, unless its key is still pressed.
+func (m *model) toggleLatch() {
+	m.isLatched = !m.isLatched
+	if !m.isLatched && m.currKey == "" {
+		if m.isSustained {
+			m.isReleaseDeferred = true
+		} else {
+			m.streamer.TriggerRelease()
+		}
+	}
+}
+
+func latchButtonHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Action == tea.MouseActionRelease && msg.Button == tea.MouseButtonLeft {
+		m.toggleLatch()
+	}
+	return m, nil
+}
+
 func upButtonHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	if msg.Action == tea.MouseActionRelease && msg.Button == tea.MouseButtonLeft {
 		return m, models.StreamerUpFunc(m)
@@ -224,6 +467,13 @@ func playStopButtonHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func bandLimitedCheckboxHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	checkboxModel, cmd := m.bandLimitedCheckbox.Update(msg)
+	m.bandLimitedCheckbox = checkboxModel.(checkbox.Model)
+	m.streamer.SetBandLimited(m.bandLimitedCheckbox.Value())
+	return m, cmd
+}
+
 func octaveSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	sliderModel, cmd := m.octaveSlider.Update(msg)
 	m.octaveSlider = sliderModel.(slider.Model)
@@ -233,10 +483,18 @@ func octaveSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 func waveformOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	optionsModel, cmd := m.waveformOptions.Update(msg)
 	m.waveformOptions = optionsModel.(options.Model[streamers.Waveform])
-	m.streamer.SetWaveform(m.currentWaveform())
+	m.updateWaveform()
 	return m, cmd
 }
 
+func (m model) updateWaveform() {
+	if m.currentWaveform() == streamers.FM {
+		m.streamer.SetFMPatch(m.fmCtrl.Patch())
+	} else {
+		m.streamer.SetWaveform(m.currentWaveform())
+	}
+}
+
 func panSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	sliderModel, cmd := m.panSlider.Update(msg)
 	m.panSlider = sliderModel.(slider.Model)
@@ -251,20 +509,71 @@ func gainSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+func smoothingSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.smoothingSlider.Update(msg)
+	m.smoothingSlider = sliderModel.(slider.Model)
+	m.streamer.SetSmoothing(m.currentSmoothing())
+	return m, cmd
+}
+
 func overtonesCtrlHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	overtonesModel, cmd := m.overtonesCtrl.Update(msg)
 	m.overtonesCtrl = overtonesModel.(overtones.Model)
-	m.streamer.SetOvertones(m.overtonesCtrl.Count(), m.overtonesCtrl.Gain())
+	if m.overtonesCtrl.IsAdditive() {
+		m.streamer.SetOvertones(0, 0)
+		m.streamer.SetPartials(m.overtonesCtrl.Partials())
+	} else {
+		m.streamer.SetPartials(nil)
+		m.streamer.SetOvertones(m.overtonesCtrl.Count(), m.overtonesCtrl.Gain())
+	}
+	return m, cmd
+}
+
+func unisonCtrlHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	unisonModel, cmd := m.unisonCtrl.Update(msg)
+	m.unisonCtrl = unisonModel.(unison.Model)
+	m.streamer.SetUnison(m.unisonCtrl.Voices(), m.unisonCtrl.Detune(), m.unisonCtrl.Spread())
 	return m, cmd
 }
 
 func chordsCtrlHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	return m.updateChordsCtrl(msg)
+}
+
+func (m model) updateChordsCtrl(msg tea.Msg) (tea.Model, tea.Cmd) {
 	chordsModel, cmd := m.chordsCtrl.Update(msg)
 	m.chordsCtrl = chordsModel.(chords.Model)
-	m.streamer.SetChord(m.chordsCtrl.Chord(), m.chordsCtrl.ArpeggioDelay())
+	m.updateChord(m.currFreq)
 	return m, cmd
 }
 
+// updateChord sets the chord of the streamer to the chord played on freq
+func (m model) updateChord(freq frequencies.Frequency) {
+	if chord := m.currentChord(freq); chord != nil {
+		m.streamer.SetChord(chord, m.chordsCtrl.ArpeggioDelay())
+	} else {
+		m.streamer.SetChordOff()
+	}
+}
+
+// currentChord returns the chord played on freq, or nil. In diatonic mode, the chord is built on the scale degree of freq,
+// within the selected key (C major if no key is selected), and notes out of the key are played alone.
+func (m model) currentChord(freq frequencies.Frequency) streamerchords.ChordType {
+	if !m.chordsCtrl.IsDiatonic() {
+		return m.chordsCtrl.Chord()
+	}
+	if freq == nil || freq.Frequency() <= 0 {
+		return nil
+	}
+
+	key, hasKey := m.scaleCtrl.Key()
+	if !hasKey {
+		key = scales.DefaultKey()
+	}
+	pitchClass := (frequencies.NearestStep(freq.Frequency())%12 + 12) % 12
+	return m.chordsCtrl.DiatonicChord(key, pitchClass)
+}
+
 func tremoloCtrlHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	tremoloModel, cmd := m.tremoloCtrl.Update(msg)
 	m.tremoloCtrl = tremoloModel.(tremolo.Model)
@@ -284,20 +593,219 @@ func envelopeCtrlHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+func fmCtrlHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	fmModel, cmd := m.fmCtrl.Update(msg)
+	m.fmCtrl = fmModel.(fm.Model)
+	m.updateWaveform()
+	return m, cmd
+}
+
+func curvatureSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.curvatureSlider.Update(msg)
+	m.curvatureSlider = sliderModel.(slider.Model)
+	m.streamer.SetCurvature(float64(m.curvatureSlider.Value()))
+	return m, cmd
+}
+
+func wavetableCtrlHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	return m.updateWavetableCtrl(msg)
+}
+
+func (m model) updateWavetableCtrl(msg tea.Msg) (tea.Model, tea.Cmd) {
+	wavetableModel, cmd := m.wavetableCtrl.Update(msg)
+	m.wavetableCtrl = wavetableModel.(wavetable.Model)
+	if m.wavetableCtrl.Bank() != m.streamer.WavetableBank() {
+		m.streamer.SetWavetableBank(m.wavetableCtrl.Bank())
+	}
+	m.streamer.SetWavetablePosition(m.wavetableCtrl.Position())
+	return m, cmd
+}
+
+func sampleCtrlHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	return m.updateSampleCtrl(msg)
+}
+
+func (m model) updateSampleCtrl(msg tea.Msg) (tea.Model, tea.Cmd) {
+	sampleModel, cmd := m.sampleCtrl.Update(msg)
+	m.sampleCtrl = sampleModel.(sample.Model)
+	if !m.sampleCtrl.Focused() {
+		m.updateSample()
+	}
+	return m, cmd
+}
+
+func (m model) updateSample() {
+	m.streamer.SetGenerator(m.sampleCtrl.Generator())
+}
+
+func tuningCtrlHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	return m.updateTuningCtrl(msg)
+}
+
+func (m model) updateTuningCtrl(msg tea.Msg) (tea.Model, tea.Cmd) {
+	tuningModel, cmd := m.tuningCtrl.Update(msg)
+	m.tuningCtrl = tuningModel.(tuning.Model)
+	if !m.tuningCtrl.Focused() {
+		m.updateKeys()
+	}
+	return m, cmd
+}
+
+func scaleCtrlHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	scaleModel, cmd := m.scaleCtrl.Update(msg)
+	m.scaleCtrl = scaleModel.(scale.Model)
+	m.updateKeys()
+	m.updateChord(m.currFreq)
+	return m, cmd
+}
+
+func keyMapOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	optionsModel, cmd := m.keyMapOptions.Update(msg)
+	m.keyMapOptions = optionsModel.(options.Model[KeyMap])
+	m.updateKeys()
+	return m, cmd
+}
+
+func keyMapInputHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	return m.updateKeyMapInput(msg)
+}
+
+func (m model) updateKeyMapInput(msg tea.Msg) (tea.Model, tea.Cmd) {
+	inputModel, cmd := m.keyMapInput.Update(msg)
+	m.keyMapInput = inputModel.(pathinput.Model)
+	if _, ok := msg.(tea.KeyMsg); ok && !m.keyMapInput.Focused() {
+		m.loadKeyMap()
+	}
+	return m, cmd
+}
+
+// loadKeyMap adds the key map of the submitted file to the options, and selects it. An empty path removes the loaded key map.
+func (m *model) loadKeyMap() {
+	path := strings.TrimSpace(m.keyMapInput.Value())
+	m.keyMapErr = nil
+	if path == "" {
+		m.keyMapOptions = options.New(KeyMaps(), false).SetValue(m.keyMapOptions.Value())
+		m.updateKeys()
+		return
+	}
+
+	keyMap, err := LoadKeyMap(path)
+	if err != nil {
+		m.keyMapErr = err
+		return
+	}
+	m.keyMapOptions = options.New(append(KeyMaps(), keyMap), false).SetValue(keyMap)
+	m.updateKeys()
+}
+
+// isLocked returns true if the keys are mapped to the notes of the selected key only
+func (m model) isLocked() bool {
+	_, hasKey := m.scaleCtrl.Key()
+	return hasKey && m.scaleCtrl.Mode() == scale.Lock
+}
+
+// updateKeys maps the keys of the selected key map to the notes of the selected tuning & key
+func (m *model) updateKeys() {
+	tuning := m.tuningCtrl.Tuning()
+	keys := m.keyMapOptions.Value().Notes()
+	key, hasKey := m.scaleCtrl.Key()
+	// the steps closest to the transposed semitones, in tunings that don't divide the octave into 12 steps.
+	// Locked keys are transposed by the notes of the key instead.
+	transposeSteps := int(math.Round(float64(m.transpose*tuning.Divisions()) / 12))
+	m.octaveToKeys = newOctaveToKeys(tuning, keys, key, m.isLocked(), transposeSteps, m.transpose)
+	m.keyboardArt = newKeyboardArt(m.keyMapOptions.Value(), tuning.Divisions())
+	m.dimmedKeys = nil
+	if hasKey && m.scaleCtrl.Mode() == scale.Highlight {
+		m.dimmedKeys = newDimmedKeys(tuning, keys, key, transposeSteps)
+	}
+}
+
+func pulseCtrlHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	pulseModel, cmd := m.pulseCtrl.Update(msg)
+	m.pulseCtrl = pulseModel.(pulse.Model)
+	m.streamer.SetPulseWidth(m.pulseCtrl.PulseWidth())
+	return m, cmd
+}
+
+func glideCtrlHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	glideModel, cmd := m.glideCtrl.Update(msg)
+	m.glideCtrl = glideModel.(glide.Model)
+	m.streamer.SetGlide(m.glideCtrl.Duration(), m.glideCtrl.TransitionType())
+	return m, cmd
+}
+
+// playKey sets the streamer to the frequency of the pressed key.
+// In legato mode, a key pressed while the previous key is still held glides without retriggering the envelope.
+func (m model) playKey(freq frequencies.Frequency) {
+	if m.chordsCtrl.IsDiatonic() {
+		m.updateChord(freq)
+	}
+
+	if !m.glideCtrl.IsOn() {
+		m.streamer.SetFrequency(freq)
+		m.streamer.TriggerAttack()
+		return
+	}
+
+	switch m.glideCtrl.Mode() {
+	case glide.Legato:
+		if m.currKey != "" {
+			m.streamer.GlideFrequency(freq)
+		} else {
+			m.streamer.SetFrequency(freq)
+			m.streamer.TriggerAttack()
+		}
+	default:
+		m.streamer.GlideFrequency(freq)
+		m.streamer.TriggerAttack()
+	}
+}
+
 func (m model) View() string {
-	return lipgloss.JoinVertical(lipgloss.Left,
+	views := []string{
 		m.renderHeader(models.ColumnWidth),
 		lipgloss.JoinHorizontal(lipgloss.Center,
 			m.renderKeyboard(),
 			m.renderOctaveSlider()),
-		m.renderWaveformOptions(),
+		m.renderKeyMapCtrl(),
+	}
+	if m.isSampler {
+		views = append(views, m.renderSampleCtrl())
+	} else {
+		views = append(views, m.renderWaveformOptions(), m.renderBandLimitedCheckbox())
+		views = append(views, m.renderWaveformCtrl()...)
+	}
+	views = append(views,
 		m.renderPanSlider(),
 		m.renderGainSlider(),
+		m.renderSmoothingSlider(),
+		m.renderTuningCtrl(),
+		m.renderScaleCtrl(),
 		m.renderChordsCtrl(),
 		m.renderOvertonesCtrl(),
+		m.renderUnisonCtrl(),
 		m.renderTremoloCtrl(),
 		m.renderEnvelopeCtrl(),
+		m.renderGlideCtrl(),
 	)
+
+	return lipgloss.JoinVertical(lipgloss.Left, views...)
+}
+
+// renderWaveformCtrl renders the controls of the current waveform, if it has any
+func (m model) renderWaveformCtrl() []string {
+	var views []string
+	switch m.currentWaveform() {
+	case streamers.FM:
+		views = append(views, m.renderFMCtrl())
+	case streamers.Pulse:
+		views = append(views, m.renderPulseCtrl())
+	case streamers.ExpSquare, streamers.LogSquare:
+		views = append(views, m.renderCurvatureSlider())
+	case streamers.Wavetable:
+		views = append(views, m.renderWavetableCtrl())
+	}
+	return views
 }
 
 func (m model) renderHeader(width int) string {
@@ -310,11 +818,16 @@ func (m model) renderHeader(width int) string {
 }
 
 func (m model) renderHeaderText(width int) string {
+	title := m.currentWaveform().String()
+	if m.isSampler {
+		title = m.sampleCtrl.Name()
+	}
+
 	var header string
 	if m.currFreq == nil {
-		header = models.HeaderStyle().Render(m.currentWaveform().String())
+		header = models.HeaderStyle().Render(title)
 	} else {
-		header = models.HeaderStyle().Render(fmt.Sprintf("%v %v (%vHz)", m.currentWaveform(), m.currFreq.Name(), m.currFreq.Frequency()))
+		header = models.HeaderStyle().Render(fmt.Sprintf("%v %v (%vHz)", title, m.currFreqName(), m.currFreq.Frequency()))
 	}
 
 	playStopButton := models.PlayButton()
@@ -323,10 +836,37 @@ func (m model) renderHeaderText(width int) string {
 	}
 
 	id := m.zonePrefix + playStopButtonId
-	view := zone.Mark(id, fmt.Sprintf("%v %v", playStopButton, header))
+	view := zone.Mark(id, fmt.Sprintf("%v %v", playStopButton, header)) + m.renderHoldState()
 	return lipgloss.NewStyle().Width(width).AlignHorizontal(lipgloss.Left).Render(view)
 }
 
+// renderHoldState renders the latch toggle, and the sustain pedal while it is held
+func (m model) renderHoldState() string {
+	latch := "latch"
+	if m.isLatched {
+		latch = models.SelectedStyle().Render(latch)
+	}
+	view := " " + zone.Mark(m.zonePrefix+latchButtonId, latch)
+
+	if m.isSustained {
+		view += " " + models.SelectedStyle().Render("sus")
+	}
+	return view
+}
+
+// currFreqName returns the name of the current note, or the recognized chord & the note if a chord is played (e.g. Am7/G G4)
+func (m model) currFreqName() string {
+	chord := m.currentChord(m.currFreq)
+	if chord == nil {
+		return m.currFreq.Name()
+	}
+
+	if recognition, ok := streamerchords.RecognizeVoiced(m.currFreq, chord, m.chordsCtrl.ChordTypes()); ok {
+		return fmt.Sprintf("%v %v", recognition, m.currFreq.Name())
+	}
+	return m.currFreq.Name()
+}
+
 func (m model) renderHeaderButtons(width int) string {
 	upButton := zone.Mark(m.zonePrefix+upButtonId, models.UpButton())
 	downButton := zone.Mark(m.zonePrefix+downButtonId, models.DownButton())
@@ -337,10 +877,23 @@ func (m model) renderHeaderButtons(width int) string {
 }
 
 func (m model) renderKeyboard() string {
-	if m.currKey != "" {
-		return strings.Replace(keyboard, m.currKey, currKeyStyle.Render(m.currKey), 1)
+	if m.currKey == "" && len(m.dimmedKeys) == 0 {
+		return m.keyboardArt
+	}
+
+	// style each key separately, as the keys can't be replaced after the styles were rendered (';' is a part of ANSI codes)
+	var sb strings.Builder
+	for _, r := range m.keyboardArt {
+		switch key := string(r); {
+		case key == m.currKey:
+			sb.WriteString(currKeyStyle.Render(key))
+		case m.dimmedKeys[key]:
+			sb.WriteString(dimmedKeyStyle.Render(key))
+		default:
+			sb.WriteRune(r)
+		}
 	}
-	return keyboard
+	return sb.String()
 }
 
 func (m model) renderWaveformOptions() string {
@@ -348,15 +901,41 @@ func (m model) renderWaveformOptions() string {
 	return zone.Mark(id, m.waveformOptions.View())
 }
 
+func (m model) renderBandLimitedCheckbox() string {
+	id := m.zonePrefix + bandLimitedCheckboxId
+	return models.LabelStyle().Render("quality") + zone.Mark(id, m.bandLimitedCheckbox.View())
+}
+
 func (m model) renderOctaveSlider() string {
 	id := m.zonePrefix + octaveSliderId
 	return marginLeftStyle.Render(
 		lipgloss.JoinVertical(lipgloss.Left,
 			"octave:",
-			zone.Mark(id, m.octaveSlider.View())+fmt.Sprintf(" %v", m.octaveSlider.Value())),
+			zone.Mark(id, m.octaveSlider.View())+fmt.Sprintf(" %v", m.octaveSlider.Value()),
+			m.renderTranspose()),
 	)
 }
 
+// renderTranspose renders the transposition, in semitones or in notes of the key when locked
+func (m model) renderTranspose() string {
+	if m.isLocked() {
+		return fmt.Sprintf("transpose %+d notes", m.transpose)
+	}
+	return fmt.Sprintf("transpose %+d", m.transpose)
+}
+
+func (m model) renderKeyMapCtrl() string {
+	keyMapOptions := zone.Mark(m.zonePrefix+keyMapOptionsId, m.keyMapOptions.View())
+	view := "file " + zone.Mark(m.zonePrefix+keyMapInputId, m.keyMapInput.View())
+	if m.keyMapErr != nil {
+		view = lipgloss.JoinVertical(lipgloss.Left, view,
+			errorStyle.Width(models.ColumnWidth-models.LabelStyle().GetWidth()).Render(m.keyMapErr.Error()))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		models.LabelStyle().Render("keys"),
+		lipgloss.JoinVertical(lipgloss.Left, keyMapOptions, view))
+}
+
 func (m model) renderPanSlider() string {
 	id := m.zonePrefix + panSliderId
 	return models.LabelStyle().Render("pan") + zone.Mark(id, m.panSlider.View()) + fmt.Sprintf(" %v", m.streamer.Pan())
@@ -367,6 +946,21 @@ func (m model) renderGainSlider() string {
 	return models.LabelStyle().Render("gain") + zone.Mark(id, m.gainSlider.View()) + fmt.Sprintf(" %v", m.streamer.Gain())
 }
 
+func (m model) renderSmoothingSlider() string {
+	id := m.zonePrefix + smoothingSliderId
+	return models.LabelStyle().Render("smooth") + zone.Mark(id, m.smoothingSlider.View()) + fmt.Sprintf(" %v", m.currentSmoothing())
+}
+
+func (m model) renderTuningCtrl() string {
+	id := m.zonePrefix + tuningCtrlId
+	return zone.Mark(id, m.tuningCtrl.View())
+}
+
+func (m model) renderScaleCtrl() string {
+	id := m.zonePrefix + scaleCtrlId
+	return zone.Mark(id, m.scaleCtrl.View())
+}
+
 func (m model) renderChordsCtrl() string {
 	id := m.zonePrefix + chordsCtrlId
 	return zone.Mark(id, m.chordsCtrl.View())
@@ -377,6 +971,11 @@ func (m model) renderOvertonesCtrl() string {
 	return zone.Mark(id, m.overtonesCtrl.View())
 }
 
+func (m model) renderUnisonCtrl() string {
+	id := m.zonePrefix + unisonCtrlId
+	return zone.Mark(id, m.unisonCtrl.View())
+}
+
 func (m model) renderTremoloCtrl() string {
 	id := m.zonePrefix + tremoloCtrlId
 	return zone.Mark(id, m.tremoloCtrl.View())
@@ -387,6 +986,36 @@ func (m model) renderEnvelopeCtrl() string {
 	return zone.Mark(id, m.envelopeCtrl.View())
 }
 
+func (m model) renderFMCtrl() string {
+	id := m.zonePrefix + fmCtrlId
+	return zone.Mark(id, m.fmCtrl.View())
+}
+
+func (m model) renderCurvatureSlider() string {
+	id := m.zonePrefix + curvatureSliderId
+	return models.LabelStyle().Render("curve") + zone.Mark(id, m.curvatureSlider.View()) + fmt.Sprintf(" %v", m.curvatureSlider.Value())
+}
+
+func (m model) renderWavetableCtrl() string {
+	id := m.zonePrefix + wavetableCtrlId
+	return zone.Mark(id, m.wavetableCtrl.View())
+}
+
+func (m model) renderSampleCtrl() string {
+	id := m.zonePrefix + sampleCtrlId
+	return zone.Mark(id, m.sampleCtrl.View())
+}
+
+func (m model) renderPulseCtrl() string {
+	id := m.zonePrefix + pulseCtrlId
+	return zone.Mark(id, m.pulseCtrl.View())
+}
+
+func (m model) renderGlideCtrl() string {
+	id := m.zonePrefix + glideCtrlId
+	return zone.Mark(id, m.glideCtrl.View())
+}
+
 func (m model) currentWaveform() streamers.Waveform {
 	return m.waveformOptions.Value()
 }
@@ -398,3 +1027,7 @@ func (m model) currentPan() float64 {
 func (m model) currentGain() float64 {
 	return float64(m.gainSlider.Value()) / float64(gainSliderRatio)
 }
+
+func (m model) currentSmoothing() time.Duration {
+	return time.Duration(m.smoothingSlider.Value()) * smoothingSliderStep
+}
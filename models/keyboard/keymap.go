@@ -0,0 +1,304 @@
+package keyboard
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// the default sustain pedal & latch keys, named as tea.KeyMsg
+	sustainKey = " "
+	latchKey   = "tab"
+)
+
+var (
+	// the semitones of the white keys, C D E F G A B
+	whiteKeySemitones = []int{0, 2, 4, 5, 7, 9, 11}
+
+	keyMapQwerty = KeyMap{
+		Name:          "qwerty",
+		Rows:          [][]string{strings.Split("awsedftgyhujkolp;", "")},
+		OctaveDown:    "z",
+		OctaveUp:      "x",
+		TransposeDown: "c",
+		TransposeUp:   "v",
+		Sustain:       sustainKey,
+		Latch:         latchKey,
+	}
+	keyMapAzerty = KeyMap{
+		Name:          "azerty",
+		Rows:          [][]string{strings.Split("qzsedftgyhujkolpm", "")},
+		OctaveDown:    "w",
+		OctaveUp:      "x",
+		TransposeDown: "c",
+		TransposeUp:   "v",
+		Sustain:       sustainKey,
+		Latch:         latchKey,
+	}
+	keyMapQwertz = KeyMap{
+		Name:          "qwertz",
+		Rows:          [][]string{strings.Split("awsedftgzhujkolpö", "")},
+		OctaveDown:    "y",
+		OctaveUp:      "x",
+		TransposeDown: "c",
+		TransposeUp:   "v",
+		Sustain:       sustainKey,
+		Latch:         latchKey,
+	}
+	keyMapDvorak = KeyMap{
+		Name:          "dvorak",
+		Rows:          [][]string{strings.Split("a,o.euyifdghtrnls", "")},
+		OctaveDown:    ";",
+		OctaveUp:      "q",
+		TransposeDown: "j",
+		TransposeUp:   "k",
+		Sustain:       sustainKey,
+		Latch:         latchKey,
+	}
+	// the layout of music trackers, the bottom row plays an octave & the top row plays the next octaves
+	keyMapTracker = KeyMap{
+		Name: "tracker",
+		Rows: [][]string{
+			strings.Split("zsxdcvgbhnjm", ""),
+			strings.Split("q2w3er5t6y7ui9o0p", ""),
+		},
+		OctaveDown:    "-",
+		OctaveUp:      "=",
+		TransposeDown: "[",
+		TransposeUp:   "]",
+		Sustain:       sustainKey,
+		Latch:         latchKey,
+	}
+)
+
+// KeyMap maps the computer keys to the keyboard notes
+type KeyMap struct {
+	Name string
+	// Rows of keys playing consecutive notes, starting at the first note of the octave.
+	// Each row continues the notes of the previous row.
+	Rows                       [][]string
+	OctaveDown, OctaveUp       string
+	TransposeDown, TransposeUp string
+	// Sustain & Latch are named as tea.KeyMsg, e.g. " " for space or "tab"
+	Sustain, Latch string
+}
+
+func KeyMaps() []KeyMap {
+	return []KeyMap{keyMapQwerty, keyMapAzerty, keyMapQwertz, keyMapDvorak, keyMapTracker}
+}
+
+// Notes returns the keys of all rows, in the order of their notes
+func (k KeyMap) Notes() []string {
+	return slices.Concat(k.Rows...)
+}
+
+func (k KeyMap) Equals(other KeyMap) bool {
+	return k.Name == other.Name
+}
+
+func (k KeyMap) String() string {
+	return k.Name
+}
+
+// validate checks that every key is used once, and that the note, octave & transpose keys are single characters
+func (k KeyMap) validate() error {
+	keys := append(k.Notes(), k.OctaveDown, k.OctaveUp, k.TransposeDown, k.TransposeUp)
+	if len(keys) == 4 {
+		return fmt.Errorf("key map %v has no notes", k.Name)
+	}
+	for _, key := range keys {
+		if utf8.RuneCountInString(key) != 1 {
+			return fmt.Errorf("key map %v: key %q should be a single character", k.Name, key)
+		}
+	}
+
+	keys = append(keys, k.Sustain, k.Latch)
+	for i, key := range keys {
+		if key == "" {
+			return fmt.Errorf("key map %v: sustain & latch keys should be set", k.Name)
+		}
+		if slices.Contains(keys[:i], key) {
+			return fmt.Errorf("key map %v: key %q is used twice", k.Name, key)
+		}
+	}
+	return nil
+}
+
+// LoadKeyMap loads a key map from a text file, named after the file. Each line is a setting & its keys, e.g.:
+//
+//	row: awsedftgyhujkolp;
+//	octave: z x
+//	transpose: c v
+//	sustain: space
+//	latch: tab
+//
+// Each row line adds a row of notes. The sustain & latch keys are optional, and default to space & tab.
+// Empty lines & lines starting with # are ignored.
+func LoadKeyMap(path string) (KeyMap, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return KeyMap{}, err
+	}
+	defer file.Close()
+
+	keyMap := KeyMap{
+		Name:    strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Sustain: sustainKey,
+		Latch:   latchKey,
+	}
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		setting, value, _ := strings.Cut(line, ":")
+		setting = strings.TrimSpace(setting)
+		switch fields := strings.Fields(value); setting {
+		case "row":
+			// spaces between the keys are ignored
+			keyMap.Rows = append(keyMap.Rows, strings.Split(strings.Join(fields, ""), ""))
+		case "octave", "transpose":
+			if len(fields) != 2 {
+				return KeyMap{}, fmt.Errorf("%v: line %v should have down & up keys", filepath.Base(path), lineNum)
+			}
+			if setting == "octave" {
+				keyMap.OctaveDown, keyMap.OctaveUp = fields[0], fields[1]
+			} else {
+				keyMap.TransposeDown, keyMap.TransposeUp = fields[0], fields[1]
+			}
+		case "sustain", "latch":
+			if len(fields) != 1 {
+				return KeyMap{}, fmt.Errorf("%v: line %v should have a single key", filepath.Base(path), lineNum)
+			}
+			key := fields[0]
+			if key == "space" {
+				key = " "
+			}
+			if setting == "sustain" {
+				keyMap.Sustain = key
+			} else {
+				keyMap.Latch = key
+			}
+		default:
+			return KeyMap{}, fmt.Errorf("%v: unknown setting %q in line %v", filepath.Base(path), setting, lineNum)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return KeyMap{}, err
+	}
+
+	if err := keyMap.validate(); err != nil {
+		return KeyMap{}, fmt.Errorf("%v: %w", filepath.Base(path), err)
+	}
+	return keyMap, nil
+}
+
+// isBlackKey returns true for the notes between the white keys, where note 0 is C.
+// In tunings that don't divide the octave into 12 steps, the white keys are the steps nearest to the 12 tone white keys.
+func isBlackKey(note, divisions int) bool {
+	step := note % divisions
+	for _, semitone := range whiteKeySemitones {
+		if int(math.Round(float64(semitone*divisions)/12)) == step {
+			return false
+		}
+	}
+	return true
+}
+
+// newKeyboardArt draws the keyboard of the key map for a tuning of the given divisions per octave, with Unicode Box-drawing characters
+// (see https://en.wikipedia.org/wiki/Box-drawing_characters), where the top row of keys is drawn first.
+func newKeyboardArt(keyMap KeyMap, divisions int) string {
+	arts := make([]string, len(keyMap.Rows))
+	firstNote := 0
+	for i, row := range keyMap.Rows {
+		arts[len(arts)-1-i] = newRowArt(row, firstNote, divisions)
+		firstNote += len(row)
+	}
+	return strings.Join(arts, "\n")
+}
+
+// newRowArt draws a row of keys, starting at firstNote, e.g.:
+//
+//	╒══╤═╤═╤═╤══╤══╕
+//	│  │w│ │e│  │  │
+//	│  └┬┘ └┬┘  │  │
+//	│ a │ s │ d │ f │
+//	└───┴───┴───┴───┘
+//
+// Consecutive black keys (e.g. in 24 tones per octave) share the border of the white keys below them.
+func newRowArt(keys []string, firstNote, divisions int) string {
+	// each white key is 3 columns wide, separated by borders. The black keys are drawn over the borders & their sides.
+	type column struct{ top, black, bottom, white string }
+	var (
+		columns    = []column{{"╒", "│", "│", "│"}}
+		border     = column{"╤", "│", "│", "│"}
+		leftEdge   = column{"╤", "│", "└", " "}
+		rightEdge  = column{"╤", "│", "┘", " "}
+		afterBlack = false
+	)
+	for i, key := range keys {
+		if isBlackKey(firstNote+i, divisions) {
+			switch {
+			case afterBlack:
+				prev := &columns[len(columns)-1]
+				prev.bottom, prev.white = "─", " "
+				columns = append(columns, column{"╤", "│", "┴", " "})
+			case len(columns) == 1:
+				// there is no white key on the left, so the black key has no border below it
+				columns[0] = column{"╒", "│", "├", "│"}
+				columns = append(columns, column{"═", key, "─", " "})
+				afterBlack = true
+				continue
+			default:
+				columns[len(columns)-1] = leftEdge
+			}
+			columns = append(columns, column{"═", key, "┬", "│"})
+			afterBlack = true
+			continue
+		}
+
+		leftSide := column{"═", " ", " ", " "}
+		if afterBlack {
+			leftSide = rightEdge
+		} else if i > 0 {
+			columns = append(columns, border)
+		}
+		columns = append(columns, leftSide, column{"═", " ", " ", key}, column{"═", " ", " ", " "})
+		afterBlack = false
+	}
+	if afterBlack {
+		// there is no white key on the right, so the last black key has no border below it
+		last := &columns[len(columns)-1]
+		last.bottom, last.white = "─", " "
+		columns = append(columns, column{"╕", "│", "┤", "│"})
+	} else {
+		columns = append(columns, column{"╕", "│", "│", "│"})
+	}
+
+	var top, black, bottom, white, base strings.Builder
+	for i, c := range columns {
+		top.WriteString(c.top)
+		black.WriteString(c.black)
+		bottom.WriteString(c.bottom)
+		white.WriteString(c.white)
+		switch {
+		case i == 0:
+			base.WriteString("└")
+		case i == len(columns)-1:
+			base.WriteString("┘")
+		case c.white == "│":
+			base.WriteString("┴")
+		default:
+			base.WriteString("─")
+		}
+	}
+	return strings.Join([]string{top.String(), black.String(), bottom.String(), white.String(), base.String()}, "\n")
+}
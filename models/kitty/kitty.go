@@ -0,0 +1,36 @@
+// Package kitty enables the kitty keyboard protocol, to receive key release events from terminals that support it.
+// See https://sw.kovidgoyal.net/kitty/keyboard-protocol/
+package kitty
+
+import (
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// pushes the disambiguate escape codes (1) & report event types (2) flags, and queries the current flags
+	enableSequence = "\x1b[>3u\x1b[?u"
+	// pops the flags pushed by enableSequence
+	disableSequence = "\x1b[<u"
+	// the flags are pushed on the alternate screen, right after the program enters it, and popped right before it exits it
+	enterAltScreenSequence = "\x1b[?1049h"
+	exitAltScreenSequence  = "\x1b[?1049l"
+
+	// the report event types flag, required for the key release events
+	flagReportEventTypes = 2
+)
+
+var program atomic.Pointer[tea.Program]
+
+// SetProgram sets the program that receives the key release messages
+func SetProgram(p *tea.Program) {
+	program.Store(p)
+}
+
+// send sends the message to the program. It is a variable so tests can capture the messages.
+var send = func(msg tea.Msg) {
+	if p := program.Load(); p != nil {
+		p.Send(msg)
+	}
+}
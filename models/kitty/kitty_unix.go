@@ -0,0 +1,253 @@
+//go:build !windows
+// +build !windows
+
+package kitty
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/HuBeZa/synth/models"
+)
+
+const (
+	esc = 0x1b
+
+	// event types
+	eventRelease = 3
+
+	// modifiers bits
+	modShift = 1
+	modAlt   = 2
+	modCtrl  = 4
+
+	// functional keys without a legacy encoding (e.g. F13, keypad & media keys) are in the unicode private use area
+	firstPrivateUseKey = 57344
+)
+
+// Input returns stdin, translating the kitty keyboard protocol key events back into legacy key sequences.
+// Key releases are sent to the program as models.KeyReleaseMsg.
+func Input() io.Reader {
+	return &reader{File: os.Stdin}
+}
+
+// Output returns stdout, enabling the kitty keyboard protocol while the program is on the alternate screen.
+// The sequences are written through the program's output, so they never interleave with the rendered frames.
+// Terminals without support ignore them.
+func Output() io.Writer {
+	return &writer{File: os.Stdout}
+}
+
+// writer wraps the terminal file, so bubbletea still detects the terminal size & restores its state
+type writer struct {
+	*os.File
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	switch string(p) {
+	case enterAltScreenSequence:
+		n, err := w.File.Write(p)
+		if err == nil {
+			_, err = w.File.WriteString(enableSequence)
+		}
+		return n, err
+	case exitAltScreenSequence:
+		if _, err := w.File.WriteString(disableSequence); err != nil {
+			return 0, err
+		}
+	}
+	return w.File.Write(p)
+}
+
+// reader wraps the terminal file, so bubbletea still sets it to raw mode & waits on its file descriptor
+type reader struct {
+	*os.File
+	// an incomplete escape sequence, from the end of the previous read
+	pending []byte
+	// translated input that didn't fit the previous read
+	output []byte
+}
+
+// Read returns the translated input. It may return no bytes at all, if the input had only key releases.
+func (r *reader) Read(p []byte) (int, error) {
+	var err error
+	if len(r.output) == 0 {
+		buf := make([]byte, len(p))
+		var n int
+		n, err = r.File.Read(buf)
+
+		var incomplete []byte
+		r.output, incomplete = translate(append(r.pending, buf[:n]...))
+		r.pending = nil
+		// like bubbletea, a sequence cut by the end of the read is completed by the next read only if the read filled the buffer.
+		// Otherwise the terminal sent it as is (e.g. alt+[ is ESC [), and it is passed through.
+		if n == len(buf) && err == nil {
+			r.pending = incomplete
+		} else {
+			r.output = append(r.output, incomplete...)
+		}
+	}
+
+	n := copy(p, r.output)
+	r.output = r.output[n:]
+	return n, err
+}
+
+// translate returns the input with the kitty key events replaced by legacy sequences,
+// and the incomplete escape sequence at the end of the input, if any
+func translate(input []byte) (output, pending []byte) {
+	output = make([]byte, 0, len(input))
+	for i := 0; i < len(input); {
+		if input[i] != esc || i+1 >= len(input) || input[i+1] != '[' {
+			output = append(output, input[i])
+			i++
+			continue
+		}
+
+		// SGR mouse events (CSI < button;x;y M) are passed through as is
+		isMouse := i+2 < len(input) && input[i+2] == '<'
+		j := i + 2
+		if isMouse {
+			j++
+		}
+		for j < len(input) && isParameter(input[j]) {
+			j++
+		}
+		if j == len(input) {
+			return output, input[i:]
+		}
+
+		if isMouse {
+			output = append(output, input[i:j+1]...)
+		} else {
+			output = append(output, translateCSI(input[i:j+1], string(input[i+2:j]), input[j])...)
+		}
+		i = j + 1
+	}
+	return output, nil
+}
+
+func isParameter(b byte) bool {
+	return (b >= '0' && b <= '9') || b == ';' || b == ':' || b == '?'
+}
+
+// translateCSI translates a CSI sequence with its parameters & final byte
+func translateCSI(sequence []byte, params string, final byte) []byte {
+	switch {
+	case final == 'u' && strings.HasPrefix(params, "?"):
+		// a response to the flags query. Terminals may support some of the flags only.
+		flags, _ := strconv.Atoi(params[1:])
+		if flags&flagReportEventTypes != 0 {
+			send(models.KeyReleaseSupportedMsg{})
+		}
+		return nil
+	case final == 'u':
+		fields := strings.Split(params, ";")
+		codepoint, _ := strconv.Atoi(subfield(fields, 0, 0))
+		mods, event := modifiersAndEvent(fields)
+		if event == eventRelease {
+			if name := keyName(codepoint, mods); name != "" {
+				send(models.KeyReleaseMsg{Key: name})
+			}
+			return nil
+		}
+		return legacyKey(codepoint, mods)
+	case strings.Contains(params, ":"):
+		// a functional key with an event type, e.g. the arrow keys (CSI 1;mods:event A)
+		fields := strings.Split(params, ";")
+		mods, event := modifiersAndEvent(fields)
+		if event == eventRelease {
+			return nil
+		}
+		if fields[0] == "1" && mods == 0 {
+			return []byte{esc, '[', final}
+		}
+		return []byte("\x1b[" + subfield(fields, 0, 0) + ";" + strconv.Itoa(mods+1) + string(final))
+	default:
+		return sequence
+	}
+}
+
+// subfield returns the colon separated subfield of the semicolon separated field, or an empty string
+func subfield(fields []string, field, sub int) string {
+	if field >= len(fields) {
+		return ""
+	}
+	subs := strings.Split(fields[field], ":")
+	if sub >= len(subs) {
+		return ""
+	}
+	return subs[sub]
+}
+
+// modifiersAndEvent returns the modifiers bits & the event type of the second field (e.g. 5:3 is ctrl release)
+func modifiersAndEvent(fields []string) (mods, event int) {
+	mods, err := strconv.Atoi(subfield(fields, 1, 0))
+	if err != nil {
+		mods = 1
+	}
+	event, err = strconv.Atoi(subfield(fields, 1, 1))
+	if err != nil {
+		event = 1
+	}
+	return mods - 1, event
+}
+
+// keyName returns the name of a key as tea.KeyMsg names its legacy encoding (see legacyKey), e.g. "A" for shift+a or "tab".
+// Functional keys without a legacy encoding have no name.
+func keyName(codepoint, mods int) string {
+	var name string
+	switch {
+	case codepoint == '\r':
+		name = "enter"
+	case codepoint == '\t':
+		name = "tab"
+	case codepoint == 0x7f:
+		name = "backspace"
+	case codepoint == esc:
+		name = "esc"
+	case codepoint >= firstPrivateUseKey && codepoint < 0xF900:
+		return ""
+	case mods&modCtrl != 0 && codepoint >= 'a' && codepoint <= 'z':
+		name = "ctrl+" + string(rune(codepoint))
+	default:
+		r := rune(codepoint)
+		if mods&modShift != 0 {
+			r = unicode.ToUpper(r)
+		}
+		name = string(r)
+	}
+
+	if mods&modAlt != 0 {
+		name = "alt+" + name
+	}
+	return name
+}
+
+// legacyKey returns the legacy encoding of a key press, e.g. ctrl+c is 0x03
+func legacyKey(codepoint, mods int) []byte {
+	var res []byte
+	switch {
+	case codepoint == '\r' || codepoint == '\t' || codepoint == 0x7f || codepoint == esc:
+		res = []byte{byte(codepoint)}
+	case codepoint >= firstPrivateUseKey && codepoint < 0xF900:
+		return nil
+	case mods&modCtrl != 0 && codepoint >= 'a' && codepoint <= 'z':
+		res = []byte{byte(codepoint) & 0x1f}
+	default:
+		r := rune(codepoint)
+		if mods&modShift != 0 {
+			r = unicode.ToUpper(r)
+		}
+		res = utf8.AppendRune(nil, r)
+	}
+
+	if mods&modAlt != 0 {
+		res = append([]byte{esc}, res...)
+	}
+	return res
+}
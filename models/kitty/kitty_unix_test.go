@@ -0,0 +1,151 @@
+//go:build !windows
+// +build !windows
+
+package kitty
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/HuBeZa/synth/models"
+)
+
+// captureMessages replaces send for the duration of the test, and returns the sent messages
+func captureMessages(t *testing.T) *[]tea.Msg {
+	t.Helper()
+	var msgs []tea.Msg
+	orig := send
+	send = func(msg tea.Msg) { msgs = append(msgs, msg) }
+	t.Cleanup(func() { send = orig })
+	return &msgs
+}
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		want        string
+		wantPending string
+		wantMsgs    []tea.Msg
+	}{
+		{name: "plain text", input: "abc", want: "abc"},
+		{name: "press", input: "\x1b[97u", want: "a"},
+		{name: "shift press", input: "\x1b[97;2u", want: "A"},
+		{name: "ctrl press", input: "\x1b[99;5u", want: "\x03"},
+		{name: "alt press", input: "\x1b[97;3u", want: "\x1ba"},
+		{name: "repeat", input: "\x1b[97;1:2u", want: "a"},
+		{name: "tab press", input: "\x1b[9u", want: "\t"},
+		{name: "release", input: "\x1b[97;1:3u", wantMsgs: []tea.Msg{models.KeyReleaseMsg{Key: "a"}}},
+		{name: "shift release", input: "\x1b[97;2:3u", wantMsgs: []tea.Msg{models.KeyReleaseMsg{Key: "A"}}},
+		{name: "space release", input: "\x1b[32;1:3u", wantMsgs: []tea.Msg{models.KeyReleaseMsg{Key: " "}}},
+		{name: "tab release", input: "\x1b[9;1:3u", wantMsgs: []tea.Msg{models.KeyReleaseMsg{Key: "tab"}}},
+		{name: "ctrl release", input: "\x1b[99;5:3u", wantMsgs: []tea.Msg{models.KeyReleaseMsg{Key: "ctrl+c"}}},
+		{name: "private use key release", input: "\x1b[57399;1:3u"},
+		{name: "press & release", input: "x\x1b[97u\x1b[97;1:3uy", want: "xay", wantMsgs: []tea.Msg{models.KeyReleaseMsg{Key: "a"}}},
+		{name: "flags response", input: "\x1b[?3u", wantMsgs: []tea.Msg{models.KeyReleaseSupportedMsg{}}},
+		{name: "flags response without event types", input: "\x1b[?1u"},
+		{name: "arrow", input: "\x1b[A", want: "\x1b[A"},
+		{name: "arrow press event", input: "\x1b[1;1:1A", want: "\x1b[A"},
+		{name: "shift arrow press event", input: "\x1b[1;2:1A", want: "\x1b[1;2A"},
+		{name: "arrow release", input: "\x1b[1;1:3A"},
+		{name: "SGR mouse press", input: "\x1b[<0;10;20M", want: "\x1b[<0;10;20M"},
+		{name: "SGR mouse release", input: "\x1b[<0;10;20m", want: "\x1b[<0;10;20m"},
+		{name: "incomplete SGR mouse", input: "a\x1b[<0;10", want: "a", wantPending: "\x1b[<0;10"},
+		{name: "incomplete sequence", input: "a\x1b[97;1:", want: "a", wantPending: "\x1b[97;1:"},
+		{name: "cut-off CSI", input: "a\x1b[", want: "a", wantPending: "\x1b["},
+		{name: "escape", input: "\x1b", want: "\x1b"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			msgs := captureMessages(t)
+			output, pending := translate([]byte(test.input))
+			if string(output) != test.want || string(pending) != test.wantPending {
+				t.Errorf("translate(%q) = %q, %q, want %q, %q", test.input, output, pending, test.want, test.wantPending)
+			}
+			if !slices.Equal(*msgs, test.wantMsgs) {
+				t.Errorf("translate(%q) sent %v, want %v", test.input, *msgs, test.wantMsgs)
+			}
+		})
+	}
+}
+
+func TestReaderSplitReads(t *testing.T) {
+	tests := []struct {
+		name string
+		// each chunk is written to the terminal before the next read
+		chunks   []string
+		readSize int
+		want     []string
+		wantMsgs []tea.Msg
+	}{
+		{
+			name:     "press completed by the next read",
+			chunks:   []string{"ab\x1b[9", "7;2u"},
+			readSize: 5,
+			want:     []string{"ab", "A"},
+		},
+		{
+			name:     "release completed by the next read",
+			chunks:   []string{"\x1b[97;", "1:3u"},
+			readSize: 5,
+			want:     []string{"", ""},
+			wantMsgs: []tea.Msg{models.KeyReleaseMsg{Key: "a"}},
+		},
+		{
+			name:     "cut-off CSI of a short read",
+			chunks:   []string{"\x1b["},
+			readSize: 8,
+			want:     []string{"\x1b["},
+		},
+		{
+			name:     "incomplete sequence of a short read",
+			chunks:   []string{"a\x1b[97;", "b"},
+			readSize: 16,
+			want:     []string{"a\x1b[97;", "b"},
+		},
+		{
+			// the completed sequence is longer than the read, so its end is returned by the next read
+			name:     "SGR mouse split across reads",
+			chunks:   []string{"\x1b[<0;1", "0;20M", ""},
+			readSize: 6,
+			want:     []string{"", "\x1b[<0;1", "0;20M"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			msgs := captureMessages(t)
+			pipeReader, pipeWriter, err := os.Pipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer pipeReader.Close()
+			defer pipeWriter.Close()
+
+			r := &reader{File: pipeReader}
+			for i, chunk := range test.chunks {
+				if chunk != "" {
+					if _, err := pipeWriter.WriteString(chunk); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				p := make([]byte, test.readSize)
+				n, err := r.Read(p)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := string(p[:n]); got != test.want[i] {
+					t.Errorf("read %v = %q, want %q", i, got, test.want[i])
+				}
+			}
+			if !slices.Equal(*msgs, test.wantMsgs) {
+				t.Errorf("sent %v, want %v", *msgs, test.wantMsgs)
+			}
+		})
+	}
+}
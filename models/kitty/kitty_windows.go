@@ -0,0 +1,20 @@
+//go:build windows
+// +build windows
+
+package kitty
+
+import (
+	"io"
+	"os"
+)
+
+// Input returns stdin, as the Windows console input doesn't support the kitty keyboard protocol
+func Input() io.Reader {
+	return os.Stdin
+}
+
+// Output returns stdout, as the Windows console doesn't support the kitty keyboard protocol,
+// so the key release is always detected by timeout
+func Output() io.Writer {
+	return os.Stdout
+}
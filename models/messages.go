@@ -27,3 +27,26 @@ func StreamerDownFunc(model StreamerModel) func() tea.Msg {
 		return StreamerDownMsg{model}
 	}
 }
+
+type RoutingChangedMsg StreamerMsg
+
+func RoutingChangedFunc(model StreamerModel) func() tea.Msg {
+	return func() tea.Msg {
+		return RoutingChangedMsg{model}
+	}
+}
+
+// RackChangedMsg is sent to all streamers whenever a streamer is added, removed or moved
+type RackChangedMsg struct{ Streamers []StreamerModel }
+
+func RackChangedFunc(streamers []StreamerModel) func() tea.Msg {
+	return func() tea.Msg {
+		return RackChangedMsg{streamers}
+	}
+}
+
+// KeyReleaseMsg is sent when a key is released, if the terminal reports key release events
+type KeyReleaseMsg struct{ Key string }
+
+// KeyReleaseSupportedMsg is sent once the terminal confirms it reports key release events
+type KeyReleaseSupportedMsg struct{}
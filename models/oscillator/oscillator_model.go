@@ -2,7 +2,7 @@ package oscillator
 
 import (
 	"fmt"
-	"regexp"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -10,50 +10,103 @@ import (
 	zone "github.com/lrstanley/bubblezone"
 
 	"github.com/HuBeZa/synth/models"
+	"github.com/HuBeZa/synth/models/base/checkbox"
 	"github.com/HuBeZa/synth/models/base/options"
+	"github.com/HuBeZa/synth/models/base/pulse"
+	"github.com/HuBeZa/synth/models/base/scale"
 	"github.com/HuBeZa/synth/models/base/slider"
+	"github.com/HuBeZa/synth/models/base/tuning"
+	"github.com/HuBeZa/synth/models/base/wavetable"
 	"github.com/HuBeZa/synth/streamers"
 	"github.com/HuBeZa/synth/streamers/frequencies"
+	"github.com/HuBeZa/synth/streamers/scales"
 )
 
 const (
 	panSliderRatio  = 10
 	gainSliderRatio = 5
+	// the frequency slider spans up to 4 octaves, limited to maxFreqSteps steps
+	maxFreqSteps = 48
+	// fine tune is up to a quarter tone up & down
+	fineSliderRatio = 10
+	fineSliderCents = 5
+	// the smoothing slider spans 0 to 100ms in 5ms steps
+	smoothingSliderStep  = 5 * time.Millisecond
+	smoothingSliderSteps = 20
+	// the noise seed slider selects one of 16 noise sequences
+	maxNoiseSeed = 16
 
 	// bubblezone ids:
-	upButtonId        = "upButton"
-	downButtonId      = "downButton"
-	closeButtonId     = "closeButton"
-	playStopButtonId  = "playStopButton"
-	waveformOptionsId = "waveformOptions"
-	octaveSliderId    = "octaveSlider"
-	panSliderId       = "panSlider"
-	gainSliderId      = "gainSlider"
-	freqSliderId      = "freqSlider"
+	upButtonId            = "upButton"
+	downButtonId          = "downButton"
+	closeButtonId         = "closeButton"
+	playStopButtonId      = "playStopButton"
+	waveformOptionsId     = "waveformOptions"
+	octaveSliderId        = "octaveSlider"
+	panSliderId           = "panSlider"
+	gainSliderId          = "gainSlider"
+	smoothingSliderId     = "smoothingSlider"
+	freqSliderId          = "freqSlider"
+	fineSliderId          = "fineSlider"
+	bandLimitedCheckboxId = "bandLimitedCheckbox"
+	pulseCtrlId           = "pulseCtrl"
+	curvatureSliderId     = "curvatureSlider"
+	noiseSeedSliderId     = "noiseSeedSlider"
+	wavetableCtrlId       = "wavetableCtrl"
+	tuningCtrlId          = "tuningCtrl"
+	scaleCtrlId           = "scaleCtrl"
 )
 
 var (
-	octaveToFrequencies = initOctaveToFrequencies()
-	cFreqIndexes        = initCFreqIndexes()
+	outOfKeyStyle = models.ForegroundColor("#585858")
 )
 
-func initOctaveToFrequencies() map[int][]frequencies.Frequency {
-	baseLow := frequencies.C0()
-	baseHigh := baseLow.ShiftOctave(4)
+// newOctaveSteps returns the steps of the frequency slider, relative to the first note of the octave.
+// When locked to a key, only the steps of the key are included, starting at the root.
+// A key may have less than 2 steps in tunings with few divisions, in which case all steps are included.
+func newOctaveSteps(tuning frequencies.Tuning, key scales.Key, isLocked bool) []int {
+	divisions := tuning.Divisions()
+	count := min(max(maxFreqSteps/divisions, 1)*divisions, maxFreqSteps) + 1
 
-	octaveToFrequencies := make(map[int][]frequencies.Frequency, 7)
+	first := 0
+	if isLocked {
+		first = key.RootStep(divisions)
+	}
+	steps := make([]int, 0, count)
+	for step := first; step < first+count; step++ {
+		// steps left unmapped by the tuning's keyboard mapping are silent in every octave
+		if tuning.Note(step).Frequency() == 0 {
+			continue
+		}
+		if !isLocked || key.ContainsStep(step, divisions) {
+			steps = append(steps, step)
+		}
+	}
+	if len(steps) < 2 && isLocked {
+		return newOctaveSteps(tuning, key, false)
+	}
+	return steps
+}
+
+// newOctaveToFrequencies maps each octave to the notes of the tuning at the given steps, relative to the first note of the octave
+func newOctaveToFrequencies(tuning frequencies.Tuning, steps []int) map[int][]frequencies.Frequency {
+	octaveToFrequencies := make(map[int][]frequencies.Frequency, 8)
 	for octaveId := -1; octaveId <= 6; octaveId++ {
-		octaveToFrequencies[octaveId] = frequencies.GetRange(baseLow.ShiftOctave(octaveId), baseHigh.ShiftOctave(octaveId))
+		// octave -1 starts at step 0
+		baseStep := (octaveId + 1) * tuning.Divisions()
+		octaveToFrequencies[octaveId] = make([]frequencies.Frequency, len(steps))
+		for i, step := range steps {
+			octaveToFrequencies[octaveId][i] = tuning.Note(baseStep + step)
+		}
 	}
 	return octaveToFrequencies
 }
 
-func initCFreqIndexes() []int {
-	isC := regexp.MustCompile("^C[0-9-]+$")
-	freq := octaveToFrequencies[0]
+// octaveIndexes returns the indexes of the octaves starts (relative to the first step), except for the first & last notes
+func octaveIndexes(tuning frequencies.Tuning, steps []int) []int {
 	res := make([]int, 0)
-	for i := 1; i < len(freq)-1; i++ {
-		if isC.MatchString(freq[i].Name()) {
+	for i := 1; i < len(steps)-1; i++ {
+		if (steps[i]-steps[0])%tuning.Divisions() == 0 {
 			res = append(res, i)
 		}
 	}
@@ -61,34 +114,63 @@ func initCFreqIndexes() []int {
 }
 
 type model struct {
-	waveformOptions options.Model[streamers.Waveform]
-	octaveSlider    slider.Model
-	panSlider       slider.Model
-	gainSlider      slider.Model
-	freqSlider      slider.Model
-	streamer        streamers.DynamicStreamer
-	zonePrefix      string
-	zoneHandlers    models.ZoneHandlers[model]
+	waveformOptions     options.Model[streamers.Waveform]
+	bandLimitedCheckbox checkbox.Model
+	octaveSlider        slider.Model
+	panSlider           slider.Model
+	gainSlider          slider.Model
+	smoothingSlider     slider.Model
+	freqSlider          slider.Model
+	fineSlider          slider.Model
+	pulseCtrl           pulse.Model
+	curvatureSlider     slider.Model
+	noiseSeedSlider     slider.Model
+	wavetableCtrl       wavetable.Model
+	tuningCtrl          tuning.Model
+	scaleCtrl           scale.Model
+	octaveSteps         []int
+	octaveToFrequencies map[int][]frequencies.Frequency
+	streamer            streamers.DynamicStreamer
+	zonePrefix          string
+	zoneHandlers        models.ZoneHandlers[model]
 }
 
 func New(sr beep.SampleRate) models.StreamerModel {
 	m := model{}
-	m.waveformOptions = options.New(streamers.AllWaveforms(), false)
+	m.waveformOptions = options.New(streamers.AllWaveforms(), false).SetWidth(models.ColumnWidth)
 	m.octaveSlider, _ = slider.New(-1, 6, 1, 3, 3)
 	m.panSlider, _ = slider.New(-panSliderRatio, panSliderRatio, 1, 0, 0)
 	m.gainSlider, _ = slider.New(0, gainSliderRatio*4, 1, gainSliderRatio, gainSliderRatio, gainSliderRatio*2, gainSliderRatio*3)
-	m.freqSlider, _ = slider.New(0, len(m.currentOctave())-1, 1, 0, cFreqIndexes...)
+	m.fineSlider, _ = slider.New(-fineSliderRatio, fineSliderRatio, 1, 0, 0)
+	m.smoothingSlider, _ = slider.New(0, smoothingSliderSteps, 1, int(streamers.DefaultSmoothingTime/smoothingSliderStep))
+	m.tuningCtrl = tuning.New()
+	m.scaleCtrl = scale.New()
+	m.updateFrequencies(0)
+	m.bandLimitedCheckbox = checkbox.New("band-limited", true)
+	m.pulseCtrl = pulse.New()
+	m.wavetableCtrl = wavetable.New()
+	m.curvatureSlider, _ = slider.New(int(streamers.MinCurvature), int(streamers.MaxCurvature), 1, int(streamers.DefaultCurvature))
+	m.noiseSeedSlider, _ = slider.New(1, maxNoiseSeed, 1, streamers.DefaultNoiseSeed)
 	m.zonePrefix = zone.NewPrefix()
 	m.zoneHandlers = models.ZoneHandlers[model]{
-		m.zonePrefix + upButtonId:        upButtonHandler,
-		m.zonePrefix + downButtonId:      downButtonHandler,
-		m.zonePrefix + closeButtonId:     closeButtonHandler,
-		m.zonePrefix + playStopButtonId:  playStopButtonHandler,
-		m.zonePrefix + waveformOptionsId: waveformOptionsHandler,
-		m.zonePrefix + octaveSliderId:    octaveSliderHandler,
-		m.zonePrefix + panSliderId:       panSliderHandler,
-		m.zonePrefix + gainSliderId:      gainSliderHandler,
-		m.zonePrefix + freqSliderId:      freqSliderHandler,
+		m.zonePrefix + upButtonId:            upButtonHandler,
+		m.zonePrefix + downButtonId:          downButtonHandler,
+		m.zonePrefix + closeButtonId:         closeButtonHandler,
+		m.zonePrefix + playStopButtonId:      playStopButtonHandler,
+		m.zonePrefix + waveformOptionsId:     waveformOptionsHandler,
+		m.zonePrefix + bandLimitedCheckboxId: bandLimitedCheckboxHandler,
+		m.zonePrefix + octaveSliderId:        octaveSliderHandler,
+		m.zonePrefix + panSliderId:           panSliderHandler,
+		m.zonePrefix + gainSliderId:          gainSliderHandler,
+		m.zonePrefix + smoothingSliderId:     smoothingSliderHandler,
+		m.zonePrefix + freqSliderId:          freqSliderHandler,
+		m.zonePrefix + fineSliderId:          fineSliderHandler,
+		m.zonePrefix + pulseCtrlId:           pulseCtrlHandler,
+		m.zonePrefix + curvatureSliderId:     curvatureSliderHandler,
+		m.zonePrefix + noiseSeedSliderId:     noiseSeedSliderHandler,
+		m.zonePrefix + wavetableCtrlId:       wavetableCtrlHandler,
+		m.zonePrefix + tuningCtrlId:          tuningCtrlHandler,
+		m.zonePrefix + scaleCtrlId:           scaleCtrlHandler,
 	}
 
 	var err error
@@ -96,6 +178,7 @@ func New(sr beep.SampleRate) models.StreamerModel {
 	if err != nil {
 		panic(err)
 	}
+	m.streamer.SetBandLimited(m.bandLimitedCheckbox.Value())
 
 	return m
 }
@@ -107,6 +190,11 @@ func (m model) Equals(other tea.Model) bool {
 	return false
 }
 
+// Focused returns true while the tuning or wavetable file path is edited
+func (m model) Focused() bool {
+	return m.tuningCtrl.Focused() || (m.currentWaveform() == streamers.Wavetable && m.wavetableCtrl.Focused())
+}
+
 func (m model) Streamer() beep.Streamer {
 	return m.streamer
 }
@@ -119,6 +207,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.MouseMsg:
 		return m.zoneHandlers.Handle(m, msg)
+	case tea.KeyMsg:
+		if m.tuningCtrl.Focused() {
+			return m.updateTuningCtrl(msg)
+		} else if m.Focused() {
+			return m.updateWavetableCtrl(msg)
+		}
 	}
 	return m, nil
 }
@@ -158,6 +252,13 @@ func waveformOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+func bandLimitedCheckboxHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	checkboxModel, cmd := m.bandLimitedCheckbox.Update(msg)
+	m.bandLimitedCheckbox = checkboxModel.(checkbox.Model)
+	m.streamer.SetBandLimited(m.bandLimitedCheckbox.Value())
+	return m, cmd
+}
+
 func octaveSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	sliderModel, cmd := m.octaveSlider.Update(msg)
 	m.octaveSlider = sliderModel.(slider.Model)
@@ -179,6 +280,13 @@ func gainSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+func smoothingSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.smoothingSlider.Update(msg)
+	m.smoothingSlider = sliderModel.(slider.Model)
+	m.streamer.SetSmoothing(m.currentSmoothing())
+	return m, cmd
+}
+
 func freqSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	sliderModel, cmd := m.freqSlider.Update(msg)
 	m.freqSlider = sliderModel.(slider.Model)
@@ -186,14 +294,114 @@ func freqSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+func fineSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.fineSlider.Update(msg)
+	m.fineSlider = sliderModel.(slider.Model)
+	m.streamer.SetFrequency(m.currentFrequency())
+	return m, cmd
+}
+
+func curvatureSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.curvatureSlider.Update(msg)
+	m.curvatureSlider = sliderModel.(slider.Model)
+	m.streamer.SetCurvature(float64(m.curvatureSlider.Value()))
+	return m, cmd
+}
+
+func noiseSeedSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.noiseSeedSlider.Update(msg)
+	m.noiseSeedSlider = sliderModel.(slider.Model)
+	m.streamer.SetNoiseSeed(uint64(m.noiseSeedSlider.Value()))
+	return m, cmd
+}
+
+func wavetableCtrlHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	return m.updateWavetableCtrl(msg)
+}
+
+func (m model) updateWavetableCtrl(msg tea.Msg) (tea.Model, tea.Cmd) {
+	wavetableModel, cmd := m.wavetableCtrl.Update(msg)
+	m.wavetableCtrl = wavetableModel.(wavetable.Model)
+	if m.wavetableCtrl.Bank() != m.streamer.WavetableBank() {
+		m.streamer.SetWavetableBank(m.wavetableCtrl.Bank())
+	}
+	m.streamer.SetWavetablePosition(m.wavetableCtrl.Position())
+	return m, cmd
+}
+
+func tuningCtrlHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	return m.updateTuningCtrl(msg)
+}
+
+func (m model) updateTuningCtrl(msg tea.Msg) (tea.Model, tea.Cmd) {
+	tuningModel, cmd := m.tuningCtrl.Update(msg)
+	m.tuningCtrl = tuningModel.(tuning.Model)
+	if !m.tuningCtrl.Focused() {
+		m.updateFrequencies(m.freqSlider.Value())
+		m.streamer.SetFrequency(m.currentFrequency())
+	}
+	return m, cmd
+}
+
+func scaleCtrlHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	scaleModel, cmd := m.scaleCtrl.Update(msg)
+	m.scaleCtrl = scaleModel.(scale.Model)
+	m.updateFrequencies(m.freqSlider.Value())
+	m.streamer.SetFrequency(m.currentFrequency())
+	return m, cmd
+}
+
+// updateFrequencies rebuilds the octaves & the frequency slider from the current tuning & key, keeping the slider at index if possible
+func (m *model) updateFrequencies(index int) {
+	tuning := m.tuningCtrl.Tuning()
+	key, hasKey := m.scaleCtrl.Key()
+	octaveSteps := newOctaveSteps(tuning, key, hasKey && m.scaleCtrl.Mode() == scale.Lock)
+	count := len(octaveSteps)
+	freqSlider, err := slider.New(0, count-1, 1, min(index, count-1), octaveIndexes(tuning, octaveSteps)...)
+	if err != nil {
+		// keep the previous frequencies
+		return
+	}
+	m.octaveSteps = octaveSteps
+	m.octaveToFrequencies = newOctaveToFrequencies(tuning, octaveSteps)
+	m.freqSlider = freqSlider
+}
+
+func pulseCtrlHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	pulseModel, cmd := m.pulseCtrl.Update(msg)
+	m.pulseCtrl = pulseModel.(pulse.Model)
+	m.streamer.SetPulseWidth(m.pulseCtrl.PulseWidth())
+	return m, cmd
+}
+
 func (m model) View() string {
-	return lipgloss.JoinVertical(lipgloss.Left,
+	views := []string{
 		m.renderHeader(models.ColumnWidth),
 		m.renderOctaveSlider(),
 		m.renderFreqSlider(),
+		m.renderFineSlider(),
 		m.renderWaveformOptions(),
+		m.renderBandLimitedCheckbox(),
+	}
+	switch m.currentWaveform() {
+	case streamers.Pulse:
+		views = append(views, m.renderPulseCtrl())
+	case streamers.ExpSquare, streamers.LogSquare:
+		views = append(views, m.renderCurvatureSlider())
+	case streamers.Wavetable:
+		views = append(views, m.renderWavetableCtrl())
+	case streamers.White, streamers.Pink, streamers.Brown:
+		views = append(views, m.renderNoiseSeedSlider())
+	}
+	views = append(views,
 		m.renderPanSlider(),
-		m.renderGainSlider())
+		m.renderGainSlider(),
+		m.renderSmoothingSlider(),
+		m.renderTuningCtrl(),
+		m.renderScaleCtrl(),
+	)
+
+	return lipgloss.JoinVertical(lipgloss.Left, views...)
 }
 
 func (m model) renderHeader(width int) string {
@@ -206,7 +414,11 @@ func (m model) renderHeader(width int) string {
 }
 
 func (m model) renderHeaderText(width int) string {
-	header := models.HeaderStyle().Render(fmt.Sprintf("%v %v (%vHz)", m.currentWaveform(), m.currentFrequency().Name(), m.currentFrequency().Frequency()))
+	style := models.HeaderStyle()
+	if !m.isInKey() {
+		style = outOfKeyStyle.Inherit(style)
+	}
+	header := style.Render(fmt.Sprintf("%v %v (%vHz)", m.currentWaveform(), m.currentFrequency().Name(), m.currentFrequency().Frequency()))
 
 	playStopButton := models.PlayButton()
 	if m.streamer.IsSilenced() {
@@ -232,6 +444,41 @@ func (m model) renderWaveformOptions() string {
 	return zone.Mark(id, m.waveformOptions.View())
 }
 
+func (m model) renderBandLimitedCheckbox() string {
+	id := m.zonePrefix + bandLimitedCheckboxId
+	return models.LabelStyle().Render("quality") + zone.Mark(id, m.bandLimitedCheckbox.View())
+}
+
+func (m model) renderCurvatureSlider() string {
+	id := m.zonePrefix + curvatureSliderId
+	return models.LabelStyle().Render("curve") + zone.Mark(id, m.curvatureSlider.View()) + fmt.Sprintf(" %v", m.curvatureSlider.Value())
+}
+
+func (m model) renderNoiseSeedSlider() string {
+	id := m.zonePrefix + noiseSeedSliderId
+	return models.LabelStyle().Render("seed") + zone.Mark(id, m.noiseSeedSlider.View()) + fmt.Sprintf(" %v", m.noiseSeedSlider.Value())
+}
+
+func (m model) renderWavetableCtrl() string {
+	id := m.zonePrefix + wavetableCtrlId
+	return zone.Mark(id, m.wavetableCtrl.View())
+}
+
+func (m model) renderPulseCtrl() string {
+	id := m.zonePrefix + pulseCtrlId
+	return zone.Mark(id, m.pulseCtrl.View())
+}
+
+func (m model) renderTuningCtrl() string {
+	id := m.zonePrefix + tuningCtrlId
+	return zone.Mark(id, m.tuningCtrl.View())
+}
+
+func (m model) renderScaleCtrl() string {
+	id := m.zonePrefix + scaleCtrlId
+	return zone.Mark(id, m.scaleCtrl.View())
+}
+
 func (m model) renderOctaveSlider() string {
 	id := m.zonePrefix + octaveSliderId
 	return models.LabelStyle().Render("octave") + zone.Mark(id, m.octaveSlider.View()) + fmt.Sprintf(" %v", m.octaveSlider.Value())
@@ -247,11 +494,21 @@ func (m model) renderGainSlider() string {
 	return models.LabelStyle().Render("gain") + zone.Mark(id, m.gainSlider.View()) + fmt.Sprintf(" %v", m.streamer.Gain())
 }
 
+func (m model) renderSmoothingSlider() string {
+	id := m.zonePrefix + smoothingSliderId
+	return models.LabelStyle().Render("smooth") + zone.Mark(id, m.smoothingSlider.View()) + fmt.Sprintf(" %v", m.currentSmoothing())
+}
+
 func (m model) renderFreqSlider() string {
 	id := m.zonePrefix + freqSliderId
 	return models.LabelStyle().Render("freq") + zone.Mark(id, m.freqSlider.View())
 }
 
+func (m model) renderFineSlider() string {
+	id := m.zonePrefix + fineSliderId
+	return models.LabelStyle().Render("fine") + zone.Mark(id, m.fineSlider.View()) + fmt.Sprintf(" %+.0f¢", m.currentFineTune())
+}
+
 func (m model) currentWaveform() streamers.Waveform {
 	return m.waveformOptions.Value()
 }
@@ -264,10 +521,28 @@ func (m model) currentGain() float64 {
 	return float64(m.gainSlider.Value()) / float64(gainSliderRatio)
 }
 
+func (m model) currentSmoothing() time.Duration {
+	return time.Duration(m.smoothingSlider.Value()) * smoothingSliderStep
+}
+
 func (m model) currentOctave() []frequencies.Frequency {
-	return octaveToFrequencies[m.octaveSlider.Value()]
+	return m.octaveToFrequencies[m.octaveSlider.Value()]
 }
 
 func (m model) currentFrequency() frequencies.Frequency {
-	return m.currentOctave()[m.freqSlider.Value()]
+	return m.currentOctave()[m.freqSlider.Value()].ShiftCents(m.currentFineTune())
+}
+
+// currentFineTune returns the fine tune in cents
+func (m model) currentFineTune() float64 {
+	return float64(m.fineSlider.Value() * fineSliderCents)
+}
+
+// isInKey returns false if the current note is out of the highlighted key
+func (m model) isInKey() bool {
+	key, hasKey := m.scaleCtrl.Key()
+	if !hasKey || m.scaleCtrl.Mode() != scale.Highlight {
+		return true
+	}
+	return key.ContainsStep(m.octaveSteps[m.freqSlider.Value()], m.tuningCtrl.Tuning().Divisions())
 }
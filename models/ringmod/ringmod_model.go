@@ -0,0 +1,270 @@
+package ringmod
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gopxl/beep/v2"
+	zone "github.com/lrstanley/bubblezone"
+
+	"github.com/HuBeZa/synth/models"
+	"github.com/HuBeZa/synth/models/base/options"
+	"github.com/HuBeZa/synth/models/base/slider"
+	"github.com/HuBeZa/synth/streamers"
+)
+
+const (
+	mixSliderRatio = 10
+	// the smoothing slider spans 0 to 100ms in 5ms steps
+	smoothingSliderStep  = 5 * time.Millisecond
+	smoothingSliderSteps = 20
+
+	// bubblezone ids:
+	upButtonId         = "upButton"
+	downButtonId       = "downButton"
+	closeButtonId      = "closeButton"
+	playStopButtonId   = "playStopButton"
+	carrierOptionsId   = "carrierOptions"
+	modulatorOptionsId = "modulatorOptions"
+	mixSliderId        = "mixSlider"
+	smoothingSliderId  = "smoothingSlider"
+)
+
+var (
+	labelStyle = lipgloss.NewStyle().Width(10)
+)
+
+type model struct {
+	carrierOptions   options.Model[models.RackInput]
+	modulatorOptions options.Model[models.RackInput]
+	mixSlider        slider.Model
+	smoothingSlider  slider.Model
+	streamer         streamers.RingModulator
+	zonePrefix       string
+	zoneHandlers     models.ZoneHandlers[model]
+}
+
+func New(sr beep.SampleRate) models.StreamerModel {
+	m := model{}
+	m.carrierOptions = options.New([]models.RackInput{}, true)
+	m.modulatorOptions = options.New([]models.RackInput{}, true)
+	m.mixSlider, _ = slider.New(0, mixSliderRatio, 1, mixSliderRatio, mixSliderRatio/2)
+	m.smoothingSlider, _ = slider.New(0, smoothingSliderSteps, 1, int(streamers.DefaultSmoothingTime/smoothingSliderStep))
+	m.zonePrefix = zone.NewPrefix()
+	m.zoneHandlers = models.ZoneHandlers[model]{
+		m.zonePrefix + upButtonId:         upButtonHandler,
+		m.zonePrefix + downButtonId:       downButtonHandler,
+		m.zonePrefix + closeButtonId:      closeButtonHandler,
+		m.zonePrefix + playStopButtonId:   playStopButtonHandler,
+		m.zonePrefix + carrierOptionsId:   carrierOptionsHandler,
+		m.zonePrefix + modulatorOptionsId: modulatorOptionsHandler,
+		m.zonePrefix + mixSliderId:        mixSliderHandler,
+		m.zonePrefix + smoothingSliderId:  smoothingSliderHandler,
+	}
+
+	var err error
+	m.streamer, err = streamers.NewRingModulator(sr, m.currentMix())
+	if err != nil {
+		panic(err)
+	}
+
+	return m
+}
+
+func (m model) Equals(other tea.Model) bool {
+	if other, ok := other.(model); ok {
+		return m.zonePrefix == other.zonePrefix
+	}
+	return false
+}
+
+func (m model) Streamer() beep.Streamer {
+	return m.streamer
+}
+
+func (m model) Inputs() []models.StreamerModel {
+	inputs := make([]models.StreamerModel, 0, 2)
+	if carrier := m.carrierOptions.Value().Model; carrier != nil {
+		inputs = append(inputs, carrier)
+	}
+	if modulator := m.modulatorOptions.Value().Model; modulator != nil {
+		inputs = append(inputs, modulator)
+	}
+	return inputs
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		return m.zoneHandlers.Handle(m, msg)
+	case models.RackChangedMsg:
+		return m.updateInputs(msg.Streamers)
+	}
+	return m, nil
+}
+
+// updateInputs refreshes the carrier & modulator options with the current rack streamers
+func (m model) updateInputs(rack []models.StreamerModel) (tea.Model, tea.Cmd) {
+	inputs := models.RackInputs(m, rack)
+
+	origCarrier, origModulator := m.carrierOptions.Value(), m.modulatorOptions.Value()
+	m.carrierOptions = options.New(inputs, true).SetValue(origCarrier)
+	m.modulatorOptions = options.New(inputs, true).SetValue(origModulator)
+
+	if m.carrierOptions.Value().Equals(origCarrier) && m.modulatorOptions.Value().Equals(origModulator) {
+		return m, nil
+	}
+
+	// one of the inputs was removed from the rack
+	m.updateStreamerInputs()
+	return m, models.RoutingChangedFunc(m)
+}
+
+func upButtonHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Action == tea.MouseActionRelease && msg.Button == tea.MouseButtonLeft {
+		return m, models.StreamerUpFunc(m)
+	}
+	return m, nil
+}
+
+func downButtonHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Action == tea.MouseActionRelease && msg.Button == tea.MouseButtonLeft {
+		return m, models.StreamerDownFunc(m)
+	}
+	return m, nil
+}
+
+func closeButtonHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Action == tea.MouseActionRelease && msg.Button == tea.MouseButtonLeft {
+		return m, models.RemoveStreamerFunc(m)
+	}
+	return m, nil
+}
+
+func playStopButtonHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Action == tea.MouseActionRelease && msg.Button == tea.MouseButtonLeft {
+		m.streamer.ToggleSilence()
+	}
+	return m, nil
+}
+
+func carrierOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	optionsModel, cmd := m.carrierOptions.Update(msg)
+	m.carrierOptions = optionsModel.(options.Model[models.RackInput])
+	m.updateStreamerInputs()
+	return m, tea.Batch(cmd, models.RoutingChangedFunc(m))
+}
+
+func modulatorOptionsHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	optionsModel, cmd := m.modulatorOptions.Update(msg)
+	m.modulatorOptions = optionsModel.(options.Model[models.RackInput])
+	m.updateStreamerInputs()
+	return m, tea.Batch(cmd, models.RoutingChangedFunc(m))
+}
+
+func mixSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.mixSlider.Update(msg)
+	m.mixSlider = sliderModel.(slider.Model)
+	m.streamer.SetMix(m.currentMix())
+	return m, cmd
+}
+
+func smoothingSliderHandler(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	sliderModel, cmd := m.smoothingSlider.Update(msg)
+	m.smoothingSlider = sliderModel.(slider.Model)
+	m.streamer.SetSmoothing(m.currentSmoothing())
+	return m, cmd
+}
+
+func (m model) updateStreamerInputs() {
+	m.streamer.SetCarrier(inputStreamer(m.carrierOptions.Value()))
+	m.streamer.SetModulator(inputStreamer(m.modulatorOptions.Value()))
+}
+
+func inputStreamer(in models.RackInput) beep.Streamer {
+	if in.Model == nil {
+		return nil
+	}
+	return in.Model.Streamer()
+}
+
+func (m model) View() string {
+	return lipgloss.JoinVertical(lipgloss.Left,
+		m.renderHeader(models.ColumnWidth),
+		m.renderCarrierOptions(),
+		m.renderModulatorOptions(),
+		m.renderMixSlider(),
+		m.renderSmoothingSlider())
+}
+
+func (m model) renderHeader(width int) string {
+	widthLeft := width * 9 / 10
+	widthRight := width - widthLeft
+
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		m.renderHeaderText(widthLeft),
+		m.renderHeaderButtons(widthRight))
+}
+
+func (m model) renderHeaderText(width int) string {
+	header := models.HeaderStyle().Render(fmt.Sprintf("ring mod %v × %v", renderInput(m.carrierOptions.Value()), renderInput(m.modulatorOptions.Value())))
+
+	playStopButton := models.PlayButton()
+	if m.streamer.IsSilenced() {
+		playStopButton = models.StopButton()
+	}
+
+	id := m.zonePrefix + playStopButtonId
+	view := zone.Mark(id, fmt.Sprintf("%v %v", playStopButton, header))
+	return lipgloss.NewStyle().Width(width).AlignHorizontal(lipgloss.Left).Render(view)
+}
+
+func renderInput(in models.RackInput) string {
+	if in.Model == nil {
+		return "?"
+	}
+	return in.String()
+}
+
+func (m model) renderHeaderButtons(width int) string {
+	upButton := zone.Mark(m.zonePrefix+upButtonId, models.UpButton())
+	downButton := zone.Mark(m.zonePrefix+downButtonId, models.DownButton())
+	closeButton := zone.Mark(m.zonePrefix+closeButtonId, models.CloseButton())
+	view := lipgloss.JoinHorizontal(lipgloss.Top, upButton, downButton, closeButton)
+
+	return lipgloss.NewStyle().Width(width).AlignHorizontal(lipgloss.Right).MarginRight(1).Render(view)
+}
+
+func (m model) renderCarrierOptions() string {
+	id := m.zonePrefix + carrierOptionsId
+	return labelStyle.Render("carrier") + zone.Mark(id, m.carrierOptions.View())
+}
+
+func (m model) renderModulatorOptions() string {
+	id := m.zonePrefix + modulatorOptionsId
+	return labelStyle.Render("modulator") + zone.Mark(id, m.modulatorOptions.View())
+}
+
+func (m model) renderMixSlider() string {
+	id := m.zonePrefix + mixSliderId
+	return labelStyle.Render("mix") + zone.Mark(id, m.mixSlider.View()) + fmt.Sprintf(" %v", m.streamer.Mix())
+}
+
+func (m model) renderSmoothingSlider() string {
+	id := m.zonePrefix + smoothingSliderId
+	return labelStyle.Render("smooth") + zone.Mark(id, m.smoothingSlider.View()) + fmt.Sprintf(" %v", m.currentSmoothing())
+}
+
+func (m model) currentMix() float64 {
+	return float64(m.mixSlider.Value()) / float64(mixSliderRatio)
+}
+
+func (m model) currentSmoothing() time.Duration {
+	return time.Duration(m.smoothingSlider.Value()) * smoothingSliderStep
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+)
+
+// RackInput is a rack streamer that can be routed into a routing model
+type RackInput struct {
+	// the streamer position in the rack
+	Index int
+	Model StreamerModel
+}
+
+func (i RackInput) Equals(other RackInput) bool {
+	if i.Model == nil || other.Model == nil {
+		return i.Model == nil && other.Model == nil
+	}
+	return i.Model.Equals(other.Model)
+}
+
+func (i RackInput) String() string {
+	return fmt.Sprintf("#%v", i.Index+1)
+}
+
+// RackInputs returns the rack streamers that can be routed into the routing model.
+// Routing models are excluded to avoid routing cycles. The inputs of other routing models are excluded too,
+// as a streamer played by several consumers would be split between them, each getting a part of its samples.
+func RackInputs(routing RoutingModel, rack []StreamerModel) []RackInput {
+	claimed := make([]StreamerModel, 0)
+	for _, streamer := range rack {
+		if other, ok := streamer.(RoutingModel); ok && !other.Equals(routing) {
+			claimed = append(claimed, other.Inputs()...)
+		}
+	}
+
+	inputs := make([]RackInput, 0, len(rack))
+	for i, streamer := range rack {
+		if _, isRouting := streamer.(RoutingModel); isRouting {
+			continue
+		}
+		isClaimed := slices.ContainsFunc(claimed, func(input StreamerModel) bool {
+			return input.Equals(streamer)
+		})
+		if !isClaimed {
+			inputs = append(inputs, RackInput{i, streamer})
+		}
+	}
+	return inputs
+}
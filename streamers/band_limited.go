@@ -0,0 +1,62 @@
+package streamers
+
+import "math"
+
+// Band-limited versions of the wave shapes with discontinuities, using PolyBLEP (polynomial band-limited step).
+// The naive shapes jump from one value to another within a single sample, which folds harmonics above Nyquist
+// back into the audible range (aliasing). PolyBLEP smooths each jump with a 2-sample polynomial residual.
+// Shapes with sharp corners (the curved squares) are smoothed the same way by PolyBLAMP, the integrated PolyBLEP.
+// The triangle's corners are mild, and sine has none, so they have little aliasing & no band-limited version.
+
+func bandLimitedSquareShape(phase, increment float64) float64 {
+	_, halfPhase := math.Modf(phase + 0.5)
+	return squareShape(phase, increment) + polyBLEP(phase, increment) - polyBLEP(halfPhase, increment)
+}
+
+func bandLimitedSawtoothShape(phase, increment float64) float64 {
+	return sawtoothShape(phase, increment) - polyBLEP(phase, increment)
+}
+
+func bandLimitedReversedSawtoothShape(phase, increment float64) float64 {
+	return reversedSawtoothShape(phase, increment) + polyBLEP(phase, increment)
+}
+
+// polyBLEP returns the residual to add to a naive upward step of size 2 at phase 0
+func polyBLEP(phase, increment float64) float64 {
+	if increment <= 0 {
+		return 0
+	}
+
+	switch {
+	case phase < increment:
+		// right after the step
+		t := phase / increment
+		return t + t - t*t - 1
+	case phase > 1-increment:
+		// right before the step
+		t := (phase - 1) / increment
+		return t*t + t + t + 1
+	default:
+		return 0
+	}
+}
+
+// polyBLAMP returns the residual to add to a naive corner at phase 0, whose slope rises by 1 per sample
+func polyBLAMP(phase, increment float64) float64 {
+	if increment <= 0 {
+		return 0
+	}
+
+	switch {
+	case phase < increment:
+		// right after the corner
+		t := 1 - phase/increment
+		return t * t * t / 6
+	case phase > 1-increment:
+		// right before the corner
+		t := (phase-1)/increment + 1
+		return t * t * t / 6
+	default:
+		return 0
+	}
+}
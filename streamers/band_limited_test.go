@@ -0,0 +1,103 @@
+package streamers
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gopxl/beep/v2"
+)
+
+const (
+	aliasTestSampleRate = beep.SampleRate(48000)
+	// the DFT size. The frequency is an odd bin, so the harmonics are on multiples of that bin,
+	// while the harmonics above Nyquist fold back between them.
+	aliasTestSize = 8192
+	aliasTestBin  = 487 // ~2853Hz, around F7
+	// the minimal alias energy reduction of the band-limited waveforms
+	minAliasReductionDb = 14
+	// the curved squares have corners rather than jumps, which alias ~17dB less to begin with
+	minCornerAliasReductionDb = 10
+)
+
+func TestBandLimitedAliasing(t *testing.T) {
+	freq := float64(aliasTestBin) * float64(aliasTestSampleRate) / aliasTestSize
+	tests := []struct {
+		waveform     Waveform
+		minReduction float64
+	}{
+		{Square, minAliasReductionDb},
+		{Pulse, minAliasReductionDb},
+		{Sawtooth, minAliasReductionDb},
+		{ReversedSawtooth, minAliasReductionDb},
+		{ExpSquare, minCornerAliasReductionDb},
+		{LogSquare, minCornerAliasReductionDb},
+	}
+
+	for _, test := range tests {
+		t.Run(test.waveform.String(), func(t *testing.T) {
+			naive := aliasRatioDb(t, test.waveform, false, freq)
+			bandLimited := aliasRatioDb(t, test.waveform, true, freq)
+			t.Logf("alias to harmonics ratio: naive %.1fdB, band-limited %.1fdB", naive, bandLimited)
+			if naive-bandLimited < test.minReduction {
+				t.Errorf("band-limited alias ratio %.1fdB, want at least %vdB below naive %.1fdB", bandLimited, test.minReduction, naive)
+			}
+		})
+	}
+}
+
+// aliasRatioDb renders the waveform & returns the energy of the bins between the harmonics, relative to the harmonics energy
+func aliasRatioDb(t *testing.T, waveform Waveform, bandLimited bool, freq float64) float64 {
+	t.Helper()
+	generator, err := waveform.streamerGenerator(bandLimited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamer, err := generator(aliasTestSampleRate, freq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first window lets the smoothed parameters settle
+	samples := make([][2]float64, aliasTestSize)
+	for range 2 {
+		if n, ok := streamer.Stream(samples); n != len(samples) || !ok {
+			t.Fatalf("streamed %v samples, want %v", n, len(samples))
+		}
+	}
+
+	var harmonics, aliases float64
+	for bin, energy := range energySpectrum(samples) {
+		switch {
+		case bin == 0:
+			// DC offset
+		case bin%aliasTestBin == 0:
+			harmonics += energy
+		default:
+			aliases += energy
+		}
+	}
+	return 10 * math.Log10(aliases/harmonics)
+}
+
+// energySpectrum returns the energy of the DFT bins of the left channel, up to Nyquist
+func energySpectrum(samples [][2]float64) []float64 {
+	n := len(samples)
+	cos := make([]float64, n)
+	sin := make([]float64, n)
+	for i := range n {
+		cos[i] = math.Cos(2 * math.Pi * float64(i) / float64(n))
+		sin[i] = math.Sin(2 * math.Pi * float64(i) / float64(n))
+	}
+
+	energy := make([]float64, n/2)
+	for bin := range energy {
+		var re, im float64
+		for i, sample := range samples {
+			k := bin * i % n
+			re += sample[0] * cos[k]
+			im -= sample[0] * sin[k]
+		}
+		energy[bin] = re*re + im*im
+	}
+	return energy
+}
@@ -1,6 +1,9 @@
 package chords
 
-import "slices"
+import (
+	"fmt"
+	"slices"
+)
 
 var (
 	chordMajor = chordType{"Major", "M", []int{0, 4, 7}}
@@ -15,6 +18,22 @@ var (
 	chordMinor    = chordType{"Minor", "m", []int{0, 3, 7}}
 	chordMinor7th = chordType{"Minor Seventh", "m7", []int{0, 3, 7, 10}}
 	chordDim      = chordType{"Diminished", "dim", []int{0, 3, 6}}
+
+	// extended chords:
+	chord9th  = chordType{"Ninth", "9", []int{0, 4, 7, 10, 14}}
+	chord11th = chordType{"Eleventh", "11", []int{0, 4, 7, 10, 14, 17}}
+	chord13th = chordType{"Thirteenth", "13", []int{0, 4, 7, 10, 14, 17, 21}}
+	chordAdd9 = chordType{"Added Ninth", "add9", []int{0, 4, 7, 14}}
+	chordSus2 = chordType{"Suspended Second", "sus2", []int{0, 2, 7}}
+	// a.k.a half diminished
+	chordMinor7thFlat5 = chordType{"Minor Seventh Flat Five", "m7b5", []int{0, 3, 6, 10}}
+	chordDim7th        = chordType{"Diminished Seventh", "dim7", []int{0, 3, 6, 9}}
+	chord7thSharp9th   = chordType{"Seventh Sharp Ninth", "7#9", []int{0, 4, 7, 10, 15}}
+)
+
+const (
+	minSemitone = -24
+	maxSemitone = 36
 )
 
 type ChordType interface {
@@ -30,6 +49,23 @@ type chordType struct {
 	semitones []int
 }
 
+// NewChordType returns a chord of the given semitones above the root
+func NewChordType(name, symbol string, semitones []int) (ChordType, error) {
+	if len(semitones) == 0 {
+		return nil, fmt.Errorf("chord %v has no semitones", symbol)
+	}
+	for _, semitone := range semitones {
+		if semitone < minSemitone || semitone > maxSemitone {
+			return nil, fmt.Errorf("chord %v semitones should be between %v to %v", symbol, minSemitone, maxSemitone)
+		}
+	}
+
+	semitonesCopy := make([]int, len(semitones))
+	copy(semitonesCopy, semitones)
+	slices.Sort(semitonesCopy)
+	return chordType{name, symbol, slices.Compact(semitonesCopy)}, nil
+}
+
 func (c chordType) Name() string {
 	return c.name
 }
@@ -66,5 +102,6 @@ func Equals(x, y ChordType) bool {
 }
 
 func ChordTypes() []ChordType {
-	return []ChordType{chordMajor, chordMinor, chord4th, chord6th, chord7th, chordMajor7th, chordMinor7th, chordAug, chordDim}
+	return []ChordType{chordMajor, chordMinor, chord4th, chord6th, chord7th, chordMajor7th, chordMinor7th, chordAug, chordDim,
+		chord9th, chord11th, chord13th, chordAdd9, chordSus2, chordMinor7thFlat5, chordDim7th, chord7thSharp9th}
 }
@@ -0,0 +1,56 @@
+package chords
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LoadChordTypes loads custom chords from a text file. Each line is a chord symbol followed by its semitones above the root,
+// e.g. "m9: 0 3 7 10 14". Empty lines & lines starting with # are ignored.
+func LoadChordTypes(path string) ([]ChordType, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	res := make([]ChordType, 0)
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		symbol, semitonesText, found := strings.Cut(line, ":")
+		symbol = strings.TrimSpace(symbol)
+		if !found || symbol == "" {
+			return nil, fmt.Errorf("%v: line %v should be a symbol & semitones, e.g. \"m9: 0 3 7 10 14\"", filepath.Base(path), lineNum)
+		}
+
+		fields := strings.Fields(semitonesText)
+		semitones := make([]int, len(fields))
+		for i, field := range fields {
+			if semitones[i], err = strconv.Atoi(field); err != nil {
+				return nil, fmt.Errorf("%v: illegal semitone %q in line %v", filepath.Base(path), field, lineNum)
+			}
+		}
+
+		chord, err := NewChordType(symbol, symbol, semitones)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", filepath.Base(path), err)
+		}
+		res = append(res, chord)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("%v: no chords found", filepath.Base(path))
+	}
+	return res, nil
+}
@@ -0,0 +1,112 @@
+package chords
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/HuBeZa/synth/streamers/scales"
+)
+
+// heptatonic is the number of degrees of the scales with diatonic chords, where every other degree is a third above the previous one
+const heptatonic = 7
+
+var romanNumerals = []string{"I", "II", "III", "IV", "V", "VI", "VII"}
+
+// DiatonicSize is the number of thirds stacked by a diatonic chord
+type DiatonicSize int
+
+const (
+	Triad      DiatonicSize = 3
+	Seventh    DiatonicSize = 4
+	Ninth      DiatonicSize = 5
+	Eleventh   DiatonicSize = 6
+	Thirteenth DiatonicSize = 7
+)
+
+func DiatonicSizes() []DiatonicSize {
+	return []DiatonicSize{Triad, Seventh, Ninth, Eleventh, Thirteenth}
+}
+
+func (s DiatonicSize) Equals(other DiatonicSize) bool {
+	return s == other
+}
+
+func (s DiatonicSize) String() string {
+	switch s {
+	case Triad:
+		return "triad"
+	case Seventh:
+		return "7th"
+	case Ninth:
+		return "9th"
+	case Eleventh:
+		return "11th"
+	case Thirteenth:
+		return "13th"
+	default:
+		return strconv.Itoa(int(s))
+	}
+}
+
+// Diatonic returns the chord of stacked thirds of the key, built on the scale degree of root (semitones above C).
+// It returns false if root is out of the key, or if the scale is not heptatonic (e.g. pentatonic),
+// as stacking every other degree of such scales doesn't stack thirds.
+func Diatonic(key scales.Key, root int, size DiatonicSize) (ChordType, bool) {
+	degrees := key.Scale.Semitones()
+	if len(degrees) != heptatonic {
+		return nil, false
+	}
+	degree := slices.Index(degrees, mod(root-key.Root, 12))
+	if degree < 0 {
+		return nil, false
+	}
+
+	semitones := make([]int, size)
+	for i := range semitones {
+		// every other degree of the scale, continuing to the next octaves
+		next := degree + 2*i
+		semitones[i] = degrees[next%len(degrees)] + 12*(next/len(degrees)) - degrees[degree]
+	}
+
+	symbol := diatonicSymbol(degree, semitones)
+	return chordType{"Diatonic " + symbol, symbol, semitones}, true
+}
+
+// diatonicSymbol returns the roman numeral of the degree, in lower case for minor & diminished chords, followed by the chord quality
+// (e.g. V7, ii7, viiø7, IVmaj9)
+func diatonicSymbol(degree int, semitones []int) string {
+	numeral := romanNumerals[degree]
+	third, fifth := semitones[1], semitones[2]
+	if third <= 3 {
+		numeral = strings.ToLower(numeral)
+	}
+
+	var quality string
+	switch {
+	case third <= 3 && fifth <= 6:
+		quality = "°"
+	case third >= 4 && fifth >= 8:
+		quality = "+"
+	}
+	if len(semitones) < 4 {
+		return numeral + quality
+	}
+
+	extension := fmt.Sprint(2*len(semitones) - 1)
+	switch seventh := semitones[3]; {
+	case quality == "°" && seventh <= 9:
+		return numeral + "°" + extension
+	case quality == "°":
+		return numeral + "ø" + extension
+	case seventh >= 11:
+		return numeral + quality + "maj" + extension
+	default:
+		return numeral + quality + extension
+	}
+}
+
+func mod(a, b int) int {
+	return ((a % b) + b) % b
+}
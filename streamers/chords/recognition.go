@@ -0,0 +1,131 @@
+package chords
+
+import (
+	"slices"
+
+	"github.com/HuBeZa/synth/streamers/frequencies"
+	"github.com/HuBeZa/synth/streamers/scales"
+)
+
+// Recognition is a chord identified from the sounding notes
+type Recognition struct {
+	// Root & Bass are semitones above C
+	Root  int
+	Bass  int
+	Chord ChordType
+	// Inversion is the index of the bass within the chord tones, 0 for root position.
+	// It is -1 if the bass is not a chord tone (e.g. C/D).
+	Inversion int
+}
+
+// String returns the chord symbol, with a slash bass if it is not the root (e.g. C, Am7/G, Cmaj7/E)
+func (r Recognition) String() string {
+	if r.Chord == nil {
+		return ""
+	}
+
+	names := scales.NoteNames()
+	symbol := r.Chord.Symbol()
+	if r.Chord.Equals(chordMajor) {
+		symbol = ""
+	}
+
+	res := names[r.Root] + symbol
+	if r.Bass != r.Root {
+		res += "/" + names[r.Bass]
+	}
+	return res
+}
+
+// RecognizeFrequencies identifies the chord of the frequencies among chordTypes, by their nearest notes
+func RecognizeFrequencies(freqs []frequencies.Frequency, chordTypes []ChordType) (Recognition, bool) {
+	midiIds := make([]int, 0, len(freqs))
+	for _, freq := range freqs {
+		if freq.Frequency() > 0 {
+			midiIds = append(midiIds, frequencies.NearestStep(freq.Frequency()))
+		}
+	}
+	return Recognize(midiIds, chordTypes)
+}
+
+// RecognizeVoiced identifies the chord of the voiced chord tones played above root, among chordTypes.
+// Only the voiced semitones sound, so the root is included only if the voicing keeps semitone 0 (e.g. not in the 1st inversion).
+func RecognizeVoiced(root frequencies.Frequency, voiced ChordType, chordTypes []ChordType) (Recognition, bool) {
+	if voiced == nil {
+		return Recognition{}, false
+	}
+
+	semitones := voiced.Semitones()
+	freqs := make([]frequencies.Frequency, 0, len(semitones))
+	for _, semitone := range semitones {
+		freqs = append(freqs, root.ShiftSemitone(semitone))
+	}
+	return RecognizeFrequencies(freqs, chordTypes)
+}
+
+// Recognize identifies the chord of the midi notes among chordTypes. The lowest note is the bass.
+// Chords whose root is the bass are preferred, and a bass out of the chord is recognized as a slash bass.
+// Earlier chord types are preferred over later ones, e.g. built-in chords over custom chords.
+func Recognize(midiIds []int, chordTypes []ChordType) (Recognition, bool) {
+	if len(midiIds) == 0 {
+		return Recognition{}, false
+	}
+
+	bass := mod(slices.Min(midiIds), 12)
+	pitchClasses := make([]int, 0, len(midiIds))
+	for _, midiId := range midiIds {
+		pitchClasses = append(pitchClasses, mod(midiId, 12))
+	}
+	slices.Sort(pitchClasses)
+	pitchClasses = slices.Compact(pitchClasses)
+	if len(pitchClasses) < 3 {
+		return Recognition{}, false
+	}
+
+	if r, ok := recognizePitchClasses(pitchClasses, bass, chordTypes); ok {
+		return r, true
+	}
+
+	// slash bass, e.g. C/D
+	withoutBass := slices.DeleteFunc(slices.Clone(pitchClasses), func(pc int) bool { return pc == bass })
+	if len(withoutBass) < 3 {
+		return Recognition{}, false
+	}
+	if r, ok := recognizePitchClasses(withoutBass, bass, chordTypes); ok {
+		r.Bass, r.Inversion = bass, -1
+		return r, true
+	}
+	return Recognition{}, false
+}
+
+// recognizePitchClasses returns the chord of exactly the sorted pitch classes, preferring the bass as the root
+func recognizePitchClasses(pitchClasses []int, bass int, chordTypes []ChordType) (Recognition, bool) {
+	var res Recognition
+	found := false
+	for _, chord := range chordTypes {
+		for _, root := range pitchClasses {
+			chordPitchClasses := make([]int, 0, len(chord.Semitones()))
+			for _, semitone := range chord.Semitones() {
+				chordPitchClasses = append(chordPitchClasses, mod(root+semitone, 12))
+			}
+			slices.Sort(chordPitchClasses)
+			if !slices.Equal(slices.Compact(chordPitchClasses), pitchClasses) {
+				continue
+			}
+
+			r := Recognition{Root: root, Bass: bass, Chord: chord, Inversion: inversion(chord, root, bass)}
+			if root == bass {
+				return r, true
+			}
+			if !found {
+				res, found = r, true
+			}
+		}
+	}
+	return res, found
+}
+
+// inversion returns the index of the bass within the chord tones, ordered by their semitones
+func inversion(chord ChordType, root, bass int) int {
+	return slices.IndexFunc(chord.Semitones(), func(semitone int) bool { return mod(root+semitone, 12) == bass })
+}
@@ -0,0 +1,71 @@
+package chords
+
+import (
+	"testing"
+
+	"github.com/HuBeZa/synth/streamers/frequencies"
+)
+
+func TestRecognize(t *testing.T) {
+	tests := []struct {
+		name    string
+		midiIds []int
+		want    string
+		wantOk  bool
+	}{
+		{name: "major", midiIds: []int{60, 64, 67}, want: "C", wantOk: true},
+		{name: "minor seventh", midiIds: []int{57, 60, 64, 67}, want: "Am7", wantOk: true},
+		{name: "1st inversion", midiIds: []int{64, 67, 72}, want: "C/E", wantOk: true},
+		{name: "doubled notes", midiIds: []int{48, 60, 64, 67, 72}, want: "C", wantOk: true},
+		{name: "added tone bass", midiIds: []int{62, 72, 76, 79}, want: "Cadd9/D", wantOk: true},
+		{name: "slash bass", midiIds: []int{54, 72, 76, 79}, want: "C/F♯", wantOk: true},
+		{name: "two notes", midiIds: []int{60, 67}},
+		{name: "no chord", midiIds: []int{60, 61, 62}},
+		{name: "empty"},
+	}
+
+	for _, test := range tests {
+		got, ok := Recognize(test.midiIds, ChordTypes())
+		if ok != test.wantOk {
+			t.Errorf("Recognize(%v) ok = %v, want %v", test.name, ok, test.wantOk)
+			continue
+		}
+		if got.String() != test.want {
+			t.Errorf("Recognize(%v) = %q, want %q", test.name, got.String(), test.want)
+		}
+	}
+}
+
+func TestRecognizeVoiced(t *testing.T) {
+	root := frequencies.FromMidi(60) // C4
+	tests := []struct {
+		name          string
+		chord         ChordType
+		inversion     int
+		voicing       Voicing
+		want          string
+		wantInversion int
+	}{
+		{name: "root position", chord: chordMajor, want: "C"},
+		{name: "1st inversion", chord: chordMajor, inversion: 1, want: "C/E", wantInversion: 1},
+		{name: "2nd inversion", chord: chordMajor, inversion: 2, want: "C/G", wantInversion: 2},
+		{name: "seventh 3rd inversion", chord: chord7th, inversion: 3, want: "C7/B♭", wantInversion: 3},
+		// drop-2 of Cmaj7 lowers G below the root: G3 C4 E4 B4
+		{name: "drop-2", chord: chordMajor7th, voicing: Drop2, want: "Cmaj7/G", wantInversion: 2},
+		// drop-2 of the 1st inversion Cmaj7 (E G B C) lowers B below E: B3 E4 G4 C5
+		{name: "1st inversion drop-2", chord: chordMajor7th, inversion: 1, voicing: Drop2, want: "Cmaj7/B", wantInversion: 3},
+	}
+
+	for _, test := range tests {
+		voiced := Voice(test.chord, test.inversion, test.voicing, false)
+		got, ok := RecognizeVoiced(root, voiced, ChordTypes())
+		if !ok {
+			t.Errorf("RecognizeVoiced(%v) not recognized", test.name)
+			continue
+		}
+		if got.String() != test.want || got.Inversion != test.wantInversion {
+			t.Errorf("RecognizeVoiced(%v) = %q inversion %v, want %q inversion %v",
+				test.name, got.String(), got.Inversion, test.want, test.wantInversion)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package chords
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+var inversionNames = []string{"root", "1st", "2nd", "3rd", "4th", "5th", "6th"}
+
+// Voicing arranges the notes of a chord across octaves
+type Voicing int
+
+const (
+	// Close keeps the notes within the smallest range
+	Close Voicing = iota
+	// Drop2 drops the second highest note by an octave
+	Drop2
+	// Drop3 drops the third highest note by an octave
+	Drop3
+	// Spread raises every other note by an octave (open voicing)
+	Spread
+)
+
+func Voicings() []Voicing {
+	return []Voicing{Close, Drop2, Drop3, Spread}
+}
+
+func (v Voicing) Equals(other Voicing) bool {
+	return v == other
+}
+
+func (v Voicing) String() string {
+	switch v {
+	case Close:
+		return "close"
+	case Drop2:
+		return "drop-2"
+	case Drop3:
+		return "drop-3"
+	case Spread:
+		return "spread"
+	default:
+		return strconv.Itoa(int(v))
+	}
+}
+
+// InversionNames returns the names of the inversions, starting at the root position
+func InversionNames() []string {
+	return slices.Clone(inversionNames)
+}
+
+// Voice returns the chord in the given inversion (the number of lowest notes raised by an octave) & voicing.
+// Octave doubling adds the lowest note an octave below.
+func Voice(chord ChordType, inversion int, voicing Voicing, octaveDoubling bool) ChordType {
+	if chord == nil {
+		return nil
+	}
+
+	semitones := chord.Semitones()
+	slices.Sort(semitones)
+	inversion = min(max(inversion, 0), len(semitones)-1)
+	for i := 0; i < inversion; i++ {
+		semitones[i] += 12
+	}
+	slices.Sort(semitones)
+
+	switch voicing {
+	case Drop2:
+		dropFromTop(semitones, 2)
+	case Drop3:
+		dropFromTop(semitones, 3)
+	case Spread:
+		for i := 1; i < len(semitones); i += 2 {
+			semitones[i] += 12
+		}
+	}
+	slices.Sort(semitones)
+
+	if octaveDoubling {
+		semitones = append([]int{semitones[0] - 12}, semitones...)
+	}
+
+	symbol := chord.Symbol()
+	if inversion > 0 {
+		symbol += fmt.Sprintf(" %v inv", inversionNames[inversion%len(inversionNames)])
+	}
+	if voicing != Close {
+		symbol += " " + voicing.String()
+	}
+	if octaveDoubling {
+		symbol += " 8va"
+	}
+	return chordType{chord.Name(), symbol, slices.Compact(semitones)}
+}
+
+// dropFromTop lowers the nth highest note by an octave, if the chord has enough notes
+func dropFromTop(semitones []int, n int) {
+	if len(semitones) >= n {
+		semitones[len(semitones)-n] -= 12
+	}
+}
@@ -0,0 +1,47 @@
+package composers
+
+import (
+	"sync/atomic"
+
+	"github.com/gopxl/beep/v2"
+)
+
+// delay is silent for length samples, and then plays the streamer
+type delay struct {
+	streamer beep.Streamer
+	pos      int
+	length   int
+	restart  atomic.Bool
+}
+
+func NewDelay(streamer beep.Streamer, length int) beep.Streamer {
+	return &delay{
+		streamer: streamer,
+		length:   length,
+	}
+}
+
+func (d *delay) Stream(samples [][2]float64) (n int, ok bool) {
+	if d.restart.CompareAndSwap(true, false) {
+		d.pos = 0
+	}
+
+	silent := min(d.length-d.pos, len(samples))
+	if silent > 0 {
+		clear(samples[:silent])
+		d.pos += silent
+	} else {
+		silent = 0
+	}
+
+	n, ok = d.streamer.Stream(samples[silent:])
+	return silent + n, ok || silent > 0
+}
+
+func (d *delay) Restart() {
+	d.restart.Store(true)
+}
+
+func (d *delay) Err() error {
+	return d.streamer.Err()
+}
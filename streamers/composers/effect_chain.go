@@ -2,10 +2,17 @@ package composers
 
 import (
 	"math"
+	"sync/atomic"
 
 	"github.com/gopxl/beep/v2"
 )
 
+// Restartable is implemented by streamers with a time dependent state.
+// Restart is safe to call while streaming, the streamer restarts from its beginning on its next Stream call.
+type Restartable interface {
+	Restart()
+}
+
 type EffectsChainBuilder interface {
 	Append(length int, transitionFunc TransitionFunc, effectFunc EffectFunc) EffectsChainBuilder
 	Loop(loop bool) EffectsChainBuilder
@@ -25,6 +32,7 @@ type effectsChain struct {
 	effectFinal bool
 	effects     []effectArgs
 	loop        bool
+	restart     atomic.Bool
 }
 
 func NewEffectsChain(streamer beep.Streamer) EffectsChainBuilder {
@@ -48,6 +56,9 @@ func (e *effectsChain) Build() beep.Streamer {
 }
 
 func (e *effectsChain) Stream(samples [][2]float64) (n int, ok bool) {
+	if e.restart.CompareAndSwap(true, false) {
+		e.timePos, e.effectPos, e.effectFinal = 0, 0, false
+	}
 	n, ok = e.streamer.Stream(samples)
 
 	currEffect := e.effects[e.effectPos]
@@ -92,6 +103,10 @@ func (e *effectsChain) nextEffect() bool {
 	return false
 }
 
+func (e *effectsChain) Restart() {
+	e.restart.Store(true)
+}
+
 func (e *effectsChain) Err() error {
 	return e.streamer.Err()
 }
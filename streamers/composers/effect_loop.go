@@ -2,6 +2,7 @@ package composers
 
 import (
 	"math"
+	"sync/atomic"
 
 	"github.com/gopxl/beep/v2"
 )
@@ -12,6 +13,7 @@ type effectLoop struct {
 	length         int
 	transitionFunc TransitionFunc
 	effectFunc     EffectFunc
+	restart        atomic.Bool
 }
 
 func NewEffectLoop(streamer beep.Streamer, length int, transitionFunc TransitionFunc, effectFunc EffectFunc) beep.Streamer {
@@ -24,6 +26,9 @@ func NewEffectLoop(streamer beep.Streamer, length int, transitionFunc Transition
 }
 
 func (e *effectLoop) Stream(samples [][2]float64) (n int, ok bool) {
+	if e.restart.CompareAndSwap(true, false) {
+		e.pos = 0
+	}
 	n, ok = e.streamer.Stream(samples)
 
 	for i := 0; i < n; i++ {
@@ -43,6 +48,10 @@ func (e *effectLoop) Stream(samples [][2]float64) (n int, ok bool) {
 	return
 }
 
+func (e *effectLoop) Restart() {
+	e.restart.Store(true)
+}
+
 func (e *effectLoop) Err() error {
 	return e.streamer.Err()
 }
@@ -0,0 +1,127 @@
+package streamers
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+)
+
+const (
+	MinCurvature     = 1.0
+	MaxCurvature     = 20.0
+	DefaultCurvature = 5.0
+)
+
+// curvatureSetter is implemented by tone sources with a curvature, that can be changed while streaming
+type curvatureSetter interface {
+	setCurvature(curvature float64)
+}
+
+// NewExpSquareGenerator returns a generator of exponential square oscillators, to be used with DynamicStreamer.SetGenerator.
+// Each edge starts slowly and accelerates exponentially towards the next level. The higher the curvature, the later the edge.
+func NewExpSquareGenerator(curvature float64, bandLimited bool) StreamerGeneratorFunc {
+	return newCurvedSquareGenerator(curvature, true, bandLimited)
+}
+
+// NewLogSquareGenerator returns a generator of logarithmic square oscillators, to be used with DynamicStreamer.SetGenerator.
+// Each edge starts fast and settles logarithmically on the next level, like a charging capacitor.
+// The higher the curvature, the closer the wave is to a square.
+func NewLogSquareGenerator(curvature float64, bandLimited bool) StreamerGeneratorFunc {
+	return newCurvedSquareGenerator(curvature, false, bandLimited)
+}
+
+func newCurvedSquareGenerator(curvature float64, exponential, bandLimited bool) StreamerGeneratorFunc {
+	return func(sampleRate beep.SampleRate, freq float64) (beep.Streamer, error) {
+		return newCurvedSquareOscillator(sampleRate, freq, curvature, exponential, bandLimited)
+	}
+}
+
+// curvedSquareOscillator is a square wave whose rise & fall edges span the whole half cycle, with an exponential curve.
+// The wave is continuous, but its slope changes abruptly where one edge ends and the next begins (at phase 0 & 0.5).
+// The higher the curvature, the sharper these corners, and the more they alias.
+type curvedSquareOscillator struct {
+	*oscillator
+	exponential bool
+	bandLimited bool
+	curvature   smoothedParam
+}
+
+func newCurvedSquareOscillator(sampleRate beep.SampleRate, freq, curvature float64, exponential, bandLimited bool) (*curvedSquareOscillator, error) {
+	if err := validateCurvature(curvature); err != nil {
+		return nil, err
+	}
+
+	o, err := newOscillator(sampleRate, freq, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &curvedSquareOscillator{
+		oscillator:  o,
+		exponential: exponential,
+		bandLimited: bandLimited,
+	}
+	c.curvature.init(curvature, sampleRate, DefaultSmoothingTime)
+	return c, nil
+}
+
+func (c *curvedSquareOscillator) Stream(samples [][2]float64) (n int, ok bool) {
+	sampleRate := float64(c.sampleRate)
+	for i := range samples {
+		curvature := c.curvature.next()
+		increment := c.frequency.next() / sampleRate
+		v := c.curvedSquare(curvature)
+		if c.bandLimited {
+			v += c.cornersBLAMP(curvature, increment)
+		}
+		v *= c.amplitude.next()
+		samples[i][0] = v
+		samples[i][1] = v
+		_, c.phase = math.Modf(c.phase + increment)
+	}
+	return len(samples), true
+}
+
+// curvedSquare rises from -1 to 1 on the first half of the cycle, and falls back on the second half
+func (c *curvedSquareOscillator) curvedSquare(curvature float64) float64 {
+	edge := 2 * c.phase
+	sign := 1.0
+	if c.phase >= 0.5 {
+		edge -= 1
+		sign = -1
+	}
+
+	var progress float64
+	if c.exponential {
+		progress = math.Expm1(curvature*edge) / math.Expm1(curvature)
+	} else {
+		progress = -math.Expm1(-curvature*edge) / -math.Expm1(-curvature)
+	}
+	return sign * (2*progress - 1)
+}
+
+// cornersBLAMP returns the residual that rounds the corners at phase 0 (slope rises) & 0.5 (slope falls).
+// Both the exponential & the logarithmic edges change their slope by 4*curvature/tanh(curvature/2) per cycle there.
+func (c *curvedSquareOscillator) cornersBLAMP(curvature, increment float64) float64 {
+	_, halfPhase := math.Modf(c.phase + 0.5)
+	slopeChange := 4 * curvature / math.Tanh(curvature/2) * increment
+	return slopeChange * (polyBLAMP(c.phase, increment) - polyBLAMP(halfPhase, increment))
+}
+
+func (c *curvedSquareOscillator) setCurvature(curvature float64) {
+	c.curvature.SetTarget(curvature)
+}
+
+func (c *curvedSquareOscillator) setSmoothing(sampleRate beep.SampleRate, smoothingTime time.Duration) {
+	c.oscillator.setSmoothing(sampleRate, smoothingTime)
+	c.curvature.setSmoothing(sampleRate, smoothingTime)
+}
+
+func validateCurvature(curvature float64) error {
+	if curvature < MinCurvature || curvature > MaxCurvature {
+		return fmt.Errorf("curvature should be between %v to %v", MinCurvature, MaxCurvature)
+	}
+	return nil
+}
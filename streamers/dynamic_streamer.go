@@ -2,6 +2,9 @@ package streamers
 
 import (
 	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
 	"sync/atomic"
 	"time"
 
@@ -9,10 +12,16 @@ import (
 	"github.com/HuBeZa/synth/streamers/composers"
 	"github.com/HuBeZa/synth/streamers/frequencies"
 	"github.com/gopxl/beep/v2"
-	"github.com/gopxl/beep/v2/effects"
 	"github.com/gopxl/beep/v2/generators"
 )
 
+const (
+	MaxUnisonVoices = 9
+
+	// seed of the random start phase of unison voices, so renders are deterministic
+	unisonSeed = 1
+)
+
 var (
 	silenceStreamer = generators.Silence(-1)
 )
@@ -31,6 +40,8 @@ type DynamicStreamer interface {
 	SetGain(gain float64) error
 	Frequency() frequencies.Frequency
 	SetFrequency(freq frequencies.Frequency) error
+	GlideFrequency(freq frequencies.Frequency) error
+	SetGlide(glideTime time.Duration, transitionType composers.TransitionType) error
 	SetTremolo(duration time.Duration, startGain, endGain float64, pulsing bool) error
 	SetTremoloOff() error
 	SetEnvelope(attack time.Duration, attackType composers.TransitionType,
@@ -39,9 +50,25 @@ type DynamicStreamer interface {
 	SetChord(chord chords.ChordType, arpeggioDelay time.Duration) error
 	SetChordOff() error
 	SetOvertones(count int, gain float64) error
+	SetPartials(partials []Partial) error
+	SetUnison(voices int, detune, spread float64) error
 	Waveform() Waveform
 	SetWaveform(waveform Waveform) error
+	BandLimited() bool
+	SetBandLimited(bandLimited bool) error
+	PulseWidth() PulseWidth
+	SetPulseWidth(pulseWidth PulseWidth) error
+	Curvature() float64
+	SetCurvature(curvature float64) error
+	WavetableBank() *WavetableBank
+	SetWavetableBank(bank *WavetableBank) error
+	WavetablePosition() WavetablePosition
+	SetWavetablePosition(position WavetablePosition) error
 	SetGenerator(streamerGenerator StreamerGeneratorFunc) error
+	SetSmoothing(smoothingTime time.Duration) error
+	SetFMPatch(patch FMPatch) error
+	// SetNoiseSeed reseeds the noise tones, so the streamer generates the same noise regardless of other streamers
+	SetNoiseSeed(seed uint64) error
 	TriggerAttack()
 	TriggerRelease()
 }
@@ -49,9 +76,40 @@ type DynamicStreamer interface {
 type dynamicStreamer struct {
 	streamerArgs streamerArgs
 	waveform     Waveform
-	silenced     atomic.Bool
-	isReleased   bool
-	streamer     atomic.Pointer[beep.Streamer]
+	bandLimited  bool
+	pulseWidth   PulseWidth
+	curvature    float64
+	wavetable    struct {
+		bank     *WavetableBank
+		position WavetablePosition
+	}
+	silenced   atomic.Bool
+	isReleased bool
+	streamer   atomic.Pointer[beep.Streamer]
+	pan        smoothedParam
+	gain       smoothedParam
+	smoothing  time.Duration
+
+	// the streamers played on attack & on release, built by update() & swapped by TriggerAttack & TriggerRelease
+	attackStreamer  *beep.Streamer
+	releaseStreamer *beep.Streamer
+
+	// tone sources (root, chord, overtones & unison voices), reused on update to keep their phase
+	tones []tone
+	// gains of the tones relative to the streamer (e.g. overtones & partials mix), reused on update so changes are smoothed
+	toneGains []*smoothedParam
+	// time dependent effects (envelopes, tremolos & arpeggio delays), restarted on every attack
+	effects []composers.Restartable
+	// some tones were dropped by the last update (e.g. above sampleRate/2 or unmapped by the tuning)
+	droppedTones bool
+	rng          *rand.Rand
+	// seed of the noise tones, which are also seeded by their index
+	noiseSeed uint64
+
+	glide struct {
+		length         int
+		transitionType composers.TransitionType
+	}
 
 	// additional tones effects:
 	chordOptions struct {
@@ -62,14 +120,22 @@ type dynamicStreamer struct {
 		count int
 		gain  float64
 	}
+	partials []Partial
+	unison   struct {
+		voices int
+		// detune of the outermost voices, in cents
+		detune float64
+		// stereo spread of the outermost voices, 0 (mono) to 1 (hard left & right)
+		spread float64
+	}
 }
 
 type streamerArgs struct {
 	sampleRate beep.SampleRate
 	generator  StreamerGeneratorFunc
 	frequency  frequencies.Frequency
-	pan        float64
-	gain       float64
+	// gain of a single tone, relative to the streamer's gain
+	toneGain float64
 
 	tremolo struct {
 		isOn      bool
@@ -91,25 +157,64 @@ type streamerArgs struct {
 	}
 }
 
+// streamerBuild collects the tones, their gains & the time dependent effects of the streamer built by update()
+type streamerBuild struct {
+	tones        []tone
+	toneGains    []*smoothedParam
+	effects      []composers.Restartable
+	droppedTones bool
+}
+
+type tone struct {
+	source    beep.Streamer
+	semitones int
+	cents     float64
+	// pan of a unison voice, nil for a single voice
+	pan *smoothedParam
+}
+
 func NewWaveformDynamicStreamer(sampleRate beep.SampleRate, freq frequencies.Frequency, pan, gain float64, waveform Waveform) (DynamicStreamer, error) {
-	generator, err := waveform.streamerGenerator()
+	generator, err := waveform.streamerGenerator(false)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewDynamicStreamer(sampleRate, freq, pan, gain, generator)
+	s, err := newDynamicStreamer(sampleRate, freq, pan, gain, generator)
+	if err != nil {
+		return nil, err
+	}
+	s.waveform = waveform
+	return s, nil
 }
 
 func NewDynamicStreamer(sampleRate beep.SampleRate, freq frequencies.Frequency, pan, gain float64, streamerGenerator StreamerGeneratorFunc) (DynamicStreamer, error) {
+	return newDynamicStreamer(sampleRate, freq, pan, gain, streamerGenerator)
+}
+
+func newDynamicStreamer(sampleRate beep.SampleRate, freq frequencies.Frequency, pan, gain float64, streamerGenerator StreamerGeneratorFunc) (*dynamicStreamer, error) {
 	s := &dynamicStreamer{
 		streamerArgs: streamerArgs{
 			sampleRate: sampleRate,
 			generator:  streamerGenerator,
 			frequency:  freq,
-			pan:        pan,
-			gain:       gain,
+			toneGain:   1,
 		},
+		waveform:   Unknown,
+		pulseWidth: DefaultPulseWidth(),
+		curvature:  DefaultCurvature,
+		smoothing:  DefaultSmoothingTime,
+		noiseSeed:  DefaultNoiseSeed,
+	}
+	s.wavetable.bank = BuiltinWavetableBank()
+	s.unison.voices = 1
+	s.rng = rand.New(rand.NewPCG(unisonSeed, 0))
+	s.wavetable.position = DefaultWavetablePosition()
+
+	if err := validatePan(pan); err != nil {
+		return nil, err
 	}
+	s.pan.init(pan, sampleRate, s.smoothing)
+	s.gain.init(gain, sampleRate, s.smoothing)
 
 	if err := s.update(); err != nil {
 		return nil, err
@@ -150,40 +255,56 @@ func (s *dynamicStreamer) ToggleSilence() {
 }
 
 func (s *dynamicStreamer) Pan() float64 {
-	return s.streamerArgs.pan
+	return s.pan.Target()
 }
 
 func (s *dynamicStreamer) SetPan(pan float64) error {
-	if pan == s.streamerArgs.pan {
-		return nil
-	}
-
-	orig := s.streamerArgs.pan
-	s.streamerArgs.pan = pan
-	if err := s.update(); err != nil {
-		s.streamerArgs.pan = orig
+	if err := validatePan(pan); err != nil {
 		return err
 	}
 
+	s.pan.SetTarget(pan)
+	return nil
+}
+
+func validatePan(pan float64) error {
+	if pan < -1 || pan > 1 {
+		return fmt.Errorf("pan should be between -1 (left channel) to 1 (right channel)")
+	}
 	return nil
 }
 
 func (s *dynamicStreamer) Gain() float64 {
-	return s.streamerArgs.gain
+	return s.gain.Target()
 }
 
 func (s *dynamicStreamer) SetGain(gain float64) error {
-	if gain == s.streamerArgs.gain {
-		return nil
-	}
+	s.gain.SetTarget(gain)
+	return nil
+}
 
-	orig := s.streamerArgs.gain
-	s.streamerArgs.gain = gain
-	if err := s.update(); err != nil {
-		s.streamerArgs.gain = orig
+// SetSmoothing sets the time constant of continuous parameters changes:
+// pan, gain, frequency, the overtones & partials mix, unison spread and the waveform parameters (e.g. pulse width)
+func (s *dynamicStreamer) SetSmoothing(smoothingTime time.Duration) error {
+	if err := validateSmoothing(smoothingTime); err != nil {
 		return err
 	}
 
+	sampleRate := s.streamerArgs.sampleRate
+	s.smoothing = smoothingTime
+	s.pan.setSmoothing(sampleRate, smoothingTime)
+	s.gain.setSmoothing(sampleRate, smoothingTime)
+	for _, tone := range s.tones {
+		if source, ok := tone.source.(smoothable); ok {
+			source.setSmoothing(sampleRate, smoothingTime)
+		}
+		if tone.pan != nil {
+			tone.pan.setSmoothing(sampleRate, smoothingTime)
+		}
+	}
+	for _, gain := range s.toneGains {
+		gain.setSmoothing(sampleRate, smoothingTime)
+	}
 	return nil
 }
 
@@ -294,6 +415,57 @@ func (s *dynamicStreamer) SetOvertones(count int, gain float64) error {
 	return nil
 }
 
+// SetPartials adds the given partials to every tone (additive synthesis). nil turns the partials off.
+func (s *dynamicStreamer) SetPartials(partials []Partial) error {
+	for _, partial := range partials {
+		if err := partial.validate(); err != nil {
+			return err
+		}
+	}
+	if slices.Equal(s.partials, partials) {
+		return nil
+	}
+
+	orig := s.partials
+	s.partials = slices.Clone(partials)
+
+	if err := s.update(); err != nil {
+		s.partials = orig
+		return err
+	}
+
+	return nil
+}
+
+// SetUnison multiplies every tone to voices, detuned by up to detune cents & spread across the stereo field (supersaw).
+// A single voice turns unison off.
+func (s *dynamicStreamer) SetUnison(voices int, detune, spread float64) error {
+	if voices < 1 || voices > MaxUnisonVoices {
+		return fmt.Errorf("unison voices should be between 1 to %v", MaxUnisonVoices)
+	}
+	if detune < 0 {
+		return fmt.Errorf("unison detune should not be negative")
+	}
+	if spread < 0 || spread > 1 {
+		return fmt.Errorf("unison spread should be between 0 to 1")
+	}
+	if s.unison.voices == voices && s.unison.detune == detune && s.unison.spread == spread {
+		return nil
+	}
+
+	orig := s.unison
+	s.unison.voices = voices
+	s.unison.detune = detune
+	s.unison.spread = spread
+
+	if err := s.update(); err != nil {
+		s.unison = orig
+		return err
+	}
+
+	return nil
+}
+
 func (s *dynamicStreamer) Frequency() frequencies.Frequency {
 	return s.streamerArgs.frequency
 }
@@ -305,7 +477,7 @@ func (s *dynamicStreamer) SetFrequency(freq frequencies.Frequency) error {
 
 	orig := s.streamerArgs.frequency
 	s.streamerArgs.frequency = freq
-	if err := s.update(); err != nil {
+	if err := s.retune(false); err != nil {
 		s.streamerArgs.frequency = orig
 		return err
 	}
@@ -313,6 +485,77 @@ func (s *dynamicStreamer) SetFrequency(freq frequencies.Frequency) error {
 	return nil
 }
 
+// GlideFrequency slides from the current frequency to freq, within the glide time set by SetGlide (portamento).
+// Tones that cannot glide are set to freq immediately.
+func (s *dynamicStreamer) GlideFrequency(freq frequencies.Frequency) error {
+	if s.glide.length == 0 {
+		return s.SetFrequency(freq)
+	}
+	if freq.Frequency() == s.streamerArgs.frequency.Frequency() {
+		return nil
+	}
+
+	orig := s.streamerArgs.frequency
+	s.streamerArgs.frequency = freq
+	if err := s.retune(true); err != nil {
+		s.streamerArgs.frequency = orig
+		return err
+	}
+
+	return nil
+}
+
+func (s *dynamicStreamer) SetGlide(glideTime time.Duration, transitionType composers.TransitionType) error {
+	if glideTime < 0 {
+		return fmt.Errorf("glide time should not be negative")
+	}
+	if transitionType.Func() == nil {
+		return fmt.Errorf("transition type unknown")
+	}
+
+	s.glide.length = s.streamerArgs.sampleRate.N(glideTime)
+	s.glide.transitionType = transitionType
+	return nil
+}
+
+// retune updates the frequency of all tones without rebuilding the streamer, if all tones are Tunable.
+// Otherwise, or if some tones were dropped at the previous frequency, the streamer is rebuilt.
+func (s *dynamicStreamer) retune(glide bool) error {
+	if s.droppedTones {
+		return s.update()
+	}
+
+	for i, tone := range s.tones {
+		tunable, ok := tone.source.(Tunable)
+		if !ok {
+			return s.update()
+		}
+
+		freq := toneFrequency(s.streamerArgs.frequency, tone.semitones, tone.cents)
+		if isUnmapped(freq, tone.semitones) {
+			// let update() drop the tone
+			return s.update()
+		}
+
+		var err error
+		if glidable, ok := tone.source.(glidable); ok && glide {
+			err = glidable.glide(freq, s.glide.length, s.glide.transitionType)
+		} else {
+			err = tunable.SetFrequency(freq)
+		}
+
+		if err != nil {
+			if i == 0 {
+				// root tone cannot be played
+				return err
+			}
+			// let update() drop the tones that cannot be played
+			return s.update()
+		}
+	}
+	return nil
+}
+
 func (s *dynamicStreamer) Waveform() Waveform {
 	return s.waveform
 }
@@ -322,7 +565,7 @@ func (s *dynamicStreamer) SetWaveform(waveform Waveform) error {
 		return nil
 	}
 
-	generator, err := waveform.streamerGenerator()
+	generator, err := s.waveformGenerator(waveform)
 	if err != nil {
 		return err
 	}
@@ -330,57 +573,274 @@ func (s *dynamicStreamer) SetWaveform(waveform Waveform) error {
 	return s.setGenerator(waveform, generator)
 }
 
+// waveformGenerator returns the generator of waveform, using the current band-limiting, pulse width, curvature & wavetable settings
+func (s *dynamicStreamer) waveformGenerator(waveform Waveform) (StreamerGeneratorFunc, error) {
+	switch waveform {
+	case Pulse:
+		return NewPulseGenerator(s.pulseWidth, s.bandLimited), nil
+	case ExpSquare:
+		return NewExpSquareGenerator(s.curvature, s.bandLimited), nil
+	case LogSquare:
+		return NewLogSquareGenerator(s.curvature, s.bandLimited), nil
+	case Wavetable:
+		return NewWavetableGenerator(s.wavetable.bank, s.wavetable.position), nil
+	default:
+		return waveform.streamerGenerator(s.bandLimited)
+	}
+}
+
+func (s *dynamicStreamer) BandLimited() bool {
+	return s.bandLimited
+}
+
+// SetBandLimited switches between the naive & the band-limited (anti-aliased) versions of the waveform.
+// Waveforms without a band-limited version, and custom generators, are not affected.
+func (s *dynamicStreamer) SetBandLimited(bandLimited bool) error {
+	if bandLimited == s.bandLimited {
+		return nil
+	}
+
+	s.bandLimited = bandLimited
+	if s.waveform.hasBandLimited() {
+		generator, err := s.waveformGenerator(s.waveform)
+		if err == nil {
+			err = s.setGenerator(s.waveform, generator)
+		}
+		if err != nil {
+			s.bandLimited = !bandLimited
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *dynamicStreamer) PulseWidth() PulseWidth {
+	return s.pulseWidth
+}
+
+// SetPulseWidth sets the duty cycle & PWM of the pulse waveform.
+// If the pulse waveform is playing, it is updated in place.
+func (s *dynamicStreamer) SetPulseWidth(pulseWidth PulseWidth) error {
+	if err := pulseWidth.validate(); err != nil {
+		return err
+	}
+
+	s.pulseWidth = pulseWidth
+	if s.waveform != Pulse {
+		return nil
+	}
+
+	// new tones (e.g. chord notes) are created with the new pulse width, existing tones are updated in place
+	s.streamerArgs.generator = NewPulseGenerator(pulseWidth, s.bandLimited)
+	for _, tone := range s.tones {
+		if source, ok := tone.source.(pulseWidthSetter); ok {
+			source.setPulseWidth(pulseWidth)
+		}
+	}
+	return nil
+}
+
+func (s *dynamicStreamer) Curvature() float64 {
+	return s.curvature
+}
+
+// SetCurvature sets the edges curvature of the exponential & logarithmic square waveforms.
+// If one of them is playing, it is updated in place.
+func (s *dynamicStreamer) SetCurvature(curvature float64) error {
+	if err := validateCurvature(curvature); err != nil {
+		return err
+	}
+
+	s.curvature = curvature
+	if s.waveform != ExpSquare && s.waveform != LogSquare {
+		return nil
+	}
+
+	// new tones (e.g. chord notes) are created with the new curvature, existing tones are updated in place
+	generator, _ := s.waveformGenerator(s.waveform)
+	s.streamerArgs.generator = generator
+	for _, tone := range s.tones {
+		if source, ok := tone.source.(curvatureSetter); ok {
+			source.setCurvature(curvature)
+		}
+	}
+	return nil
+}
+
+func (s *dynamicStreamer) WavetableBank() *WavetableBank {
+	return s.wavetable.bank
+}
+
+// SetWavetableBank sets the frames of the wavetable waveform
+func (s *dynamicStreamer) SetWavetableBank(bank *WavetableBank) error {
+	if bank == nil {
+		return fmt.Errorf("wavetable bank is missing")
+	}
+
+	orig := s.wavetable.bank
+	s.wavetable.bank = bank
+	if s.waveform != Wavetable {
+		return nil
+	}
+
+	// tones of the previous bank cannot be reused
+	if err := s.setGenerator(Wavetable, NewWavetableGenerator(bank, s.wavetable.position)); err != nil {
+		s.wavetable.bank = orig
+		return err
+	}
+	return nil
+}
+
+func (s *dynamicStreamer) WavetablePosition() WavetablePosition {
+	return s.wavetable.position
+}
+
+// SetWavetablePosition sets the position of the wavetable waveform, and its modulation.
+// If the wavetable waveform is playing, it is updated in place.
+func (s *dynamicStreamer) SetWavetablePosition(position WavetablePosition) error {
+	if err := position.validate(); err != nil {
+		return err
+	}
+
+	s.wavetable.position = position
+	if s.waveform != Wavetable {
+		return nil
+	}
+
+	// new tones (e.g. chord notes) are created with the new position, existing tones are updated in place
+	s.streamerArgs.generator = NewWavetableGenerator(s.wavetable.bank, position)
+	for _, tone := range s.tones {
+		if source, ok := tone.source.(wavetablePositionSetter); ok {
+			source.setWavetablePosition(position)
+		}
+	}
+	return nil
+}
+
 func (s *dynamicStreamer) SetGenerator(streamerGenerator StreamerGeneratorFunc) error {
 	return s.setGenerator(Unknown, streamerGenerator)
 }
 
+// SetFMPatch switches to FM waveform, with the given patch
+func (s *dynamicStreamer) SetFMPatch(patch FMPatch) error {
+	if err := patch.validate(); err != nil {
+		return err
+	}
+	return s.setGenerator(FM, NewFMGenerator(patch))
+}
+
 func (s *dynamicStreamer) setGenerator(waveform Waveform, streamerGenerator StreamerGeneratorFunc) error {
-	orig := s.streamerArgs.generator
-	s.streamerArgs.generator = streamerGenerator
+	orig, origWaveform, origTones := s.streamerArgs.generator, s.waveform, s.tones
+	s.streamerArgs.generator, s.waveform = streamerGenerator, waveform
+	// tones of the previous generator cannot be reused
+	s.tones = nil
 	if err := s.update(); err != nil {
-		s.streamerArgs.generator = orig
+		s.streamerArgs.generator, s.waveform, s.tones = orig, origWaveform, origTones
 		return err
 	}
-	s.waveform = waveform
 	return nil
 }
 
+// SetNoiseSeed reseeds the noise tones in place, and the noise tones created later on
+func (s *dynamicStreamer) SetNoiseSeed(seed uint64) error {
+	s.noiseSeed = seed
+	for i, tone := range s.tones {
+		if source, ok := tone.source.(seedable); ok {
+			source.setSeed(seed, uint64(i))
+		}
+	}
+	return nil
+}
+
+// TriggerAttack restarts the tones & their time dependent effects in place, so a note change doesn't rebuild the streamer
 func (s *dynamicStreamer) TriggerAttack() {
 	s.isReleased = false
-	// update() forces restart of time dependent effects
-	s.update()
+	for _, effect := range s.effects {
+		effect.Restart()
+	}
+	for _, tone := range s.tones {
+		if source, ok := tone.source.(retriggerable); ok {
+			source.retrigger()
+		}
+	}
+	s.streamer.Store(s.attackStreamer)
 	s.Unsilence()
 }
 
 func (s *dynamicStreamer) TriggerRelease() {
+	// one-shot notes are played to their end
+	if s.isOneShot() {
+		return
+	}
 	s.isReleased = true
-	streamer := SetRelease(s.getStreamer(), s.streamerArgs.envelope.sustain, s.streamerArgs.envelope.release, s.streamerArgs.envelope.releaseType)
-	s.streamer.Store(&streamer)
+	for _, tone := range s.tones {
+		if source, ok := tone.source.(releasable); ok {
+			source.release()
+		}
+	}
+	if release, ok := (*s.releaseStreamer).(composers.Restartable); ok {
+		release.Restart()
+	}
+	s.streamer.Store(s.releaseStreamer)
 }
 
+// isOneShot returns true if all tones are played by one-shot sources, so the release is ignored
+func (s *dynamicStreamer) isOneShot() bool {
+	for _, tone := range s.tones {
+		if source, ok := tone.source.(oneShotter); !ok || !source.isOneShot() {
+			return false
+		}
+	}
+	return len(s.tones) > 0
+}
+
+// update rebuilds the streamer, on structural changes only (e.g. waveform, chord or envelope changes)
 func (s *dynamicStreamer) update() error {
-	streamer, err := createStreamer(s.streamerArgs)
+	build := &streamerBuild{tones: make([]tone, 0, len(s.tones))}
+
+	streamer, err := s.createStreamer(build, s.streamerArgs, 0, 0)
 	if err != nil {
 		return err
 	}
 
-	if s.chordOptions.chord != nil {
-		streamer = s.addChord(streamer)
+	// noise has no pitch, so chords, overtones & partials would only multiply it
+	if !s.waveform.isNoise() {
+		if s.chordOptions.chord != nil {
+			streamer = s.addChord(build, streamer)
+		}
+
+		if s.overtones.count > 0 {
+			streamer = s.addOvertones(build, streamer)
+		}
+
+		if len(s.partials) > 0 {
+			streamer = s.addPartials(build, streamer)
+		}
 	}
 
-	if s.overtones.count > 0 {
-		streamer = s.addOvertones(streamer)
+	streamer = &panGain{
+		streamer: streamer,
+		pan:      &s.pan,
+		gain:     &s.gain,
 	}
 
+	release := SetRelease(streamer, s.streamerArgs.envelope.sustain, s.streamerArgs.envelope.release, s.streamerArgs.envelope.releaseType)
+
 	if s.isReleased {
 		s.Silence()
 	}
 
+	s.tones = build.tones
+	s.toneGains = build.toneGains
+	s.effects = build.effects
+	s.droppedTones = build.droppedTones
+	s.attackStreamer, s.releaseStreamer = &streamer, &release
 	s.streamer.Store(&streamer)
 	return nil
 }
 
-func (s *dynamicStreamer) addChord(rootStreamer beep.Streamer) beep.Streamer {
+func (s *dynamicStreamer) addChord(build *streamerBuild, rootStreamer beep.Streamer) beep.Streamer {
 	mixer := &beep.Mixer{}
 	for i, semitone := range s.chordOptions.chord.Semitones() {
 		if semitone == 0 {
@@ -388,78 +848,209 @@ func (s *dynamicStreamer) addChord(rootStreamer beep.Streamer) beep.Streamer {
 			continue
 		}
 
-		argsCopy := s.streamerArgs
-		argsCopy.frequency = s.streamerArgs.frequency.ShiftSemitone(semitone)
-		if semitoneStreamer, err := createStreamer(argsCopy); err == nil {
+		if semitoneStreamer, err := s.createStreamer(build, s.streamerArgs, semitone, 0); err == nil {
 			if s.chordOptions.arpeggioDelay > 0 {
 				// Delay each tone, up to 2 delays. After that play all remaining tones together.
 				delay := time.Duration(min(i, 2)) * s.chordOptions.arpeggioDelay
-				time.AfterFunc(delay, func() { mixer.Add(semitoneStreamer) })
-			} else {
-				mixer.Add(semitoneStreamer)
+				semitoneStreamer = composers.NewDelay(semitoneStreamer, s.streamerArgs.sampleRate.N(delay))
+				build.addEffect(semitoneStreamer)
 			}
+			mixer.Add(semitoneStreamer)
+		} else {
+			build.droppedTones = true
 		}
 	}
 
 	return mixer
 }
 
-func (s *dynamicStreamer) addOvertones(rootStreamer beep.Streamer) beep.Streamer {
+func (s *dynamicStreamer) addOvertones(build *streamerBuild, rootStreamer beep.Streamer) beep.Streamer {
 	mixer := &beep.Mixer{}
 	mixer.Add(rootStreamer)
 
 	for i := 1; i <= s.overtones.count; i++ {
 		argsCopy := s.streamerArgs
-		argsCopy.frequency = s.streamerArgs.frequency.ShiftOctave(i)
-		argsCopy.gain *= s.overtones.gain
+		argsCopy.toneGain *= s.overtones.gain
 
 		// note that some overtones may not be created because they will overpass sampleRate/2
-		if overtone, err := createStreamer(argsCopy); err == nil {
+		if overtone, err := s.createStreamer(build, argsCopy, i*12, 0); err == nil {
 			mixer.Add(overtone)
+		} else {
+			build.droppedTones = true
 		}
 	}
 
 	return mixer
 }
 
-func createStreamer(args streamerArgs) (beep.Streamer, error) {
+func (s *dynamicStreamer) addPartials(build *streamerBuild, rootStreamer beep.Streamer) beep.Streamer {
+	mixer := &beep.Mixer{}
+	mixer.Add(rootStreamer)
+
+	for _, partial := range s.partials {
+		if partial.Amplitude == 0 {
+			continue
+		}
+
+		argsCopy := s.streamerArgs
+		argsCopy.toneGain *= partial.Amplitude
+
+		// note that some partials may not be created because they will overpass sampleRate/2
+		if streamer, err := s.createStreamer(build, argsCopy, 0, partial.cents()); err == nil {
+			mixer.Add(streamer)
+		} else {
+			build.droppedTones = true
+		}
+	}
+
+	return mixer
+}
+
+// createStreamer creates a single tone, shifted by the given semitones & cents from the root frequency.
+// The tone source is reused from the previous update if possible, to keep its phase continuous.
+func (s *dynamicStreamer) createStreamer(build *streamerBuild, args streamerArgs, semitones int, cents float64) (beep.Streamer, error) {
 	if args.generator == nil {
 		return nil, fmt.Errorf("streamer generator is empty")
 	}
-	if args.pan < -1 || args.pan > 1 {
-		return nil, fmt.Errorf("pan should be between -1 (left channel) to 1 (right channel)")
-	}
 
-	streamer, err := args.generator(args.sampleRate, args.frequency.Frequency())
+	streamer, err := s.addUnison(build, args, semitones, cents)
 	if err != nil {
 		return nil, err
 	}
 
-	if args.pan != 0 {
-		streamer = &effects.Pan{
-			Streamer: streamer,
-			Pan:      args.pan,
-		}
+	// reuse the gain of the previous update, so mix changes (e.g. the overtones gain) are smoothed
+	i := len(build.toneGains)
+	gain := &smoothedParam{}
+	if i < len(s.toneGains) {
+		gain = s.toneGains[i]
+	} else {
+		gain.init(args.toneGain, args.sampleRate, s.smoothing)
 	}
-
-	if args.gain != 1 {
-		streamer = &effects.Gain{
-			Streamer: streamer,
-			Gain:     args.gain - 1,
+	if args.toneGain != 1 || gain.Target() != 1 {
+		gain.SetTarget(args.toneGain)
+		streamer = &panGain{
+			streamer: streamer,
+			gain:     gain,
 		}
 	}
+	build.toneGains = append(build.toneGains, gain)
 
 	if args.tremolo.isOn {
 		streamer = Tremolo(streamer, args.tremolo.length, args.tremolo.startGain, args.tremolo.endGain, args.tremolo.pulsing)
+		build.addEffect(streamer)
 	}
 
 	if args.envelope.isOn {
 		streamer = SetAttackDecaySustain(streamer, args.envelope.attack, args.envelope.attackType, args.envelope.decay, args.envelope.decayType, args.envelope.sustain)
+		build.addEffect(streamer)
 	}
 
 	return streamer, nil
 }
 
+// addUnison creates the unison voices of a single tone, detuned around its frequency & spread across the stereo field.
+// Without unison (or with noise, which cannot be detuned), the tone source is returned as is.
+func (s *dynamicStreamer) addUnison(build *streamerBuild, args streamerArgs, semitones int, cents float64) (beep.Streamer, error) {
+	if s.unison.voices <= 1 || s.waveform.isNoise() {
+		source, _, err := s.createToneSource(len(build.tones), args, semitones, cents)
+		if err != nil {
+			return nil, err
+		}
+		build.tones = append(build.tones, tone{source: source, semitones: semitones, cents: cents})
+		return source, nil
+	}
+
+	mixer := &beep.Mixer{}
+	for voice := range s.unison.voices {
+		// -1 (lowest & leftmost voice) to 1 (highest & rightmost voice)
+		offset := 2*float64(voice)/float64(s.unison.voices-1) - 1
+		voiceCents := cents + offset*s.unison.detune
+
+		i := len(build.tones)
+		source, isNew, err := s.createToneSource(i, args, semitones, voiceCents)
+		if err != nil {
+			// the voice may overpass sampleRate/2
+			build.droppedTones = true
+			continue
+		}
+		if source, ok := source.(phaseSetter); ok && isNew {
+			source.setPhase(s.rng.Float64())
+		}
+
+		// reuse the pan of the previous update, so spread changes are smoothed
+		pan := &smoothedParam{}
+		if i < len(s.tones) && s.tones[i].pan != nil {
+			pan = s.tones[i].pan
+			pan.SetTarget(offset * s.unison.spread)
+		} else {
+			pan.init(offset*s.unison.spread, args.sampleRate, s.smoothing)
+		}
+		gain := &smoothedParam{}
+		gain.init(1/math.Sqrt(float64(s.unison.voices)), args.sampleRate, s.smoothing)
+
+		build.tones = append(build.tones, tone{source, semitones, voiceCents, pan})
+		mixer.Add(&panGain{
+			streamer: source,
+			pan:      pan,
+			gain:     gain,
+		})
+	}
+
+	if mixer.Len() == 0 {
+		return nil, fmt.Errorf("none of the unison voices can be played")
+	}
+	return mixer, nil
+}
+
+// createToneSource returns the source of the i-th tone. isNew is false if the source was reused from the previous update.
+func (s *dynamicStreamer) createToneSource(i int, args streamerArgs, semitones int, cents float64) (source beep.Streamer, isNew bool, err error) {
+	freq := toneFrequency(args.frequency, semitones, cents)
+	if isUnmapped(freq, semitones) {
+		return nil, false, fmt.Errorf("tone is unmapped by the tuning")
+	}
+	if i < len(s.tones) {
+		if tunable, ok := s.tones[i].source.(Tunable); ok {
+			if err := tunable.SetFrequency(freq); err != nil {
+				return nil, false, err
+			}
+			return s.tones[i].source, false, nil
+		}
+	}
+
+	source, err = args.generator(args.sampleRate, freq)
+	if err != nil {
+		return nil, false, err
+	}
+	if source, ok := source.(smoothable); ok {
+		source.setSmoothing(args.sampleRate, s.smoothing)
+	}
+	if source, ok := source.(seedable); ok {
+		source.setSeed(s.noiseSeed, uint64(i))
+	}
+	return source, true, nil
+}
+
+// addEffect collects the streamer, if it has a time dependent state
+func (b *streamerBuild) addEffect(streamer beep.Streamer) {
+	if effect, ok := streamer.(composers.Restartable); ok {
+		b.effects = append(b.effects, effect)
+	}
+}
+
+func toneFrequency(root frequencies.Frequency, semitones int, cents float64) float64 {
+	freq := frequencies.ShiftSemitoneFrequency(root, semitones)
+	if cents != 0 {
+		freq *= math.Pow(2, cents/1200)
+	}
+	return freq
+}
+
+// isUnmapped returns true for a tone shifted from the root to a step left unmapped by the tuning's keyboard mapping,
+// which would otherwise be played as a 0Hz (DC) tone
+func isUnmapped(freq float64, semitones int) bool {
+	return freq == 0 && semitones != 0
+}
+
 func (s *dynamicStreamer) getStreamer() beep.Streamer {
 	return *s.streamer.Load()
 }
@@ -0,0 +1,336 @@
+package streamers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+
+	"github.com/HuBeZa/synth/streamers/composers"
+)
+
+const (
+	MinFMOperators = 2
+	MaxFMOperators = 4
+
+	// phase shift (in radians) caused by a modulator with full level
+	fmModulationIndex = 4.0
+)
+
+// FMAlgorithm defines the connections between the FM operators.
+// Operator 0 is always a carrier. A modulator always has a higher index than the operator it modulates.
+type FMAlgorithm int
+
+const (
+	// FMStack - each operator modulates the previous one: n → ... → 2 → 1
+	FMStack FMAlgorithm = iota
+	// FMBranch - all operators modulate operator 1
+	FMBranch
+	// FMPairs - 2 → 1, 4 → 3. Operators 1 & 3 are carriers
+	FMPairs
+	// FMParallel - no modulation, all operators are carriers (additive synthesis)
+	FMParallel
+)
+
+func FMAlgorithms() []FMAlgorithm {
+	return []FMAlgorithm{FMStack, FMBranch, FMPairs, FMParallel}
+}
+
+func (a FMAlgorithm) Equals(other FMAlgorithm) bool {
+	return a == other
+}
+
+func (a FMAlgorithm) String() string {
+	switch a {
+	case FMStack:
+		return "stack"
+	case FMBranch:
+		return "branch"
+	case FMPairs:
+		return "pairs"
+	case FMParallel:
+		return "parallel"
+	default:
+		return strconv.Itoa(int(a))
+	}
+}
+
+// modulators returns the indexes of the operators modulating op
+func (a FMAlgorithm) modulators(op, count int) []int {
+	res := make([]int, 0, count-1)
+	switch a {
+	case FMStack:
+		if op+1 < count {
+			res = append(res, op+1)
+		}
+	case FMBranch:
+		if op == 0 {
+			for i := 1; i < count; i++ {
+				res = append(res, i)
+			}
+		}
+	case FMPairs:
+		if op%2 == 0 && op+1 < count {
+			res = append(res, op+1)
+		}
+	}
+	return res
+}
+
+func (a FMAlgorithm) isCarrier(op int) bool {
+	switch a {
+	case FMPairs:
+		return op%2 == 0
+	case FMParallel:
+		return true
+	default:
+		return op == 0
+	}
+}
+
+type FMOperator struct {
+	// frequency ratio relative to the played note
+	Ratio float64
+	// detune in cents
+	Detune float64
+	// output level, 0 to 1. For modulators, this is the modulation depth
+	Level float64
+
+	Attack  time.Duration
+	Decay   time.Duration
+	Sustain float64
+	// Release fades the operator out on key release. 0 keeps the operator at its level, so only the voice envelope releases it.
+	Release time.Duration
+}
+
+type FMPatch struct {
+	Operators []FMOperator
+	Algorithm FMAlgorithm
+	// self modulation of the last operator, 0 to 1
+	Feedback float64
+}
+
+func DefaultFMPatch() FMPatch {
+	return FMPatch{
+		Operators: []FMOperator{
+			{Ratio: 1, Level: 1, Sustain: 1},
+			{Ratio: 2, Level: 0.5, Decay: 500 * time.Millisecond, Sustain: 0.2},
+		},
+		Algorithm: FMStack,
+	}
+}
+
+func (p FMPatch) validate() error {
+	if len(p.Operators) < MinFMOperators || len(p.Operators) > MaxFMOperators {
+		return fmt.Errorf("FM patch should have %v to %v operators", MinFMOperators, MaxFMOperators)
+	}
+	if p.Feedback < 0 || p.Feedback > 1 {
+		return fmt.Errorf("FM feedback should be between 0 to 1")
+	}
+	for _, op := range p.Operators {
+		if op.Ratio <= 0 {
+			return fmt.Errorf("FM operator ratio should be positive")
+		}
+		if op.Level < 0 || op.Level > 1 || op.Sustain < 0 || op.Sustain > 1 {
+			return fmt.Errorf("FM operator level & sustain should be between 0 to 1")
+		}
+		if op.Attack < 0 || op.Decay < 0 || op.Release < 0 {
+			return fmt.Errorf("FM operator envelope times should not be negative")
+		}
+	}
+	return nil
+}
+
+// NewFMGenerator returns a generator of FM voices, to be used with DynamicStreamer.SetGenerator
+func NewFMGenerator(patch FMPatch) StreamerGeneratorFunc {
+	return func(sampleRate beep.SampleRate, freq float64) (beep.Streamer, error) {
+		return newFMVoice(sampleRate, freq, patch)
+	}
+}
+
+type fmOperator struct {
+	// frequency multiplier, relative to the voice frequency
+	ratio   float64
+	phase   float64
+	level   float64
+	attack  int
+	decay   int
+	sustain float64
+	release int
+	pos     int
+	// position in the release stage, or -1 until the key release
+	releasePos int
+	// attack-decay-sustain gain of the last sample before the release, which the release starts from
+	gain float64
+	out  float64
+}
+
+type fmVoice struct {
+	sampleRate beep.SampleRate
+	frequency  glidingParam
+	operators  []fmOperator
+	modulators [][]int
+	carriers   []int
+	feedback   float64
+	// last two outputs of the feedback operator
+	feedbackOut [2]float64
+	restart     atomic.Bool
+	releasing   atomic.Bool
+}
+
+func newFMVoice(sampleRate beep.SampleRate, freq float64, patch FMPatch) (beep.Streamer, error) {
+	if err := patch.validate(); err != nil {
+		return nil, err
+	}
+	if err := validateFrequency(sampleRate, freq); err != nil {
+		return nil, err
+	}
+
+	count := len(patch.Operators)
+	v := &fmVoice{
+		sampleRate: sampleRate,
+		operators:  make([]fmOperator, count),
+		modulators: make([][]int, count),
+		carriers:   make([]int, 0, count),
+		feedback:   patch.Feedback,
+	}
+
+	for i, op := range patch.Operators {
+		v.operators[i] = fmOperator{
+			ratio:      op.Ratio * math.Pow(2, op.Detune/1200),
+			level:      op.Level,
+			attack:     sampleRate.N(op.Attack),
+			decay:      sampleRate.N(op.Decay),
+			sustain:    op.Sustain,
+			release:    sampleRate.N(op.Release),
+			releasePos: -1,
+		}
+		v.modulators[i] = patch.Algorithm.modulators(i, count)
+		if patch.Algorithm.isCarrier(i) {
+			v.carriers = append(v.carriers, i)
+		}
+	}
+
+	v.frequency.init(freq, sampleRate, DefaultSmoothingTime)
+	return v, nil
+}
+
+func (v *fmVoice) Stream(samples [][2]float64) (n int, ok bool) {
+	if v.restart.CompareAndSwap(true, false) {
+		for i := range v.operators {
+			v.operators[i].pos = 0
+			v.operators[i].releasePos = -1
+		}
+	}
+	if v.releasing.CompareAndSwap(true, false) {
+		for i := range v.operators {
+			v.operators[i].releasePos = 0
+		}
+	}
+
+	sampleRate := float64(v.sampleRate)
+	last := len(v.operators) - 1
+	for i := range samples {
+		dt := v.frequency.next() / sampleRate
+		// modulators have higher indexes, so they are calculated first
+		for j := last; j >= 0; j-- {
+			op := &v.operators[j]
+			modulation := 0.0
+			for _, k := range v.modulators[j] {
+				modulation += v.operators[k].out
+			}
+			if j == last {
+				modulation += v.feedback * (v.feedbackOut[0] + v.feedbackOut[1]) / 2
+			}
+
+			increment := dt * op.ratio
+			op.out = op.level * op.envelope() * math.Sin(2*math.Pi*op.phase+modulation*fmModulationIndex)
+			if increment >= 0.5 {
+				// the operator overpasses sampleRate/2, and would alias
+				op.out = 0
+			}
+			_, op.phase = math.Modf(op.phase + increment)
+		}
+		v.feedbackOut[0], v.feedbackOut[1] = v.feedbackOut[1], v.operators[last].out
+
+		out := 0.0
+		for _, j := range v.carriers {
+			out += v.operators[j].out
+		}
+		out /= float64(len(v.carriers))
+
+		samples[i][0] = out
+		samples[i][1] = out
+	}
+	return len(samples), true
+}
+
+func (*fmVoice) Err() error {
+	return nil
+}
+
+func (v *fmVoice) Frequency() float64 {
+	return v.frequency.Target()
+}
+
+func (v *fmVoice) SetFrequency(freq float64) error {
+	if err := validateFrequency(v.sampleRate, freq); err != nil {
+		return err
+	}
+	v.frequency.SetTarget(freq)
+	return nil
+}
+
+func (v *fmVoice) glide(freq float64, length int, transitionType composers.TransitionType) error {
+	if err := validateFrequency(v.sampleRate, freq); err != nil {
+		return err
+	}
+	v.frequency.glideTo(freq, length, transitionType)
+	return nil
+}
+
+func (v *fmVoice) setSmoothing(sampleRate beep.SampleRate, smoothingTime time.Duration) {
+	v.frequency.setSmoothing(sampleRate, smoothingTime)
+}
+
+// retrigger restarts the operators envelopes
+func (v *fmVoice) retrigger() {
+	v.releasing.Store(false)
+	v.restart.Store(true)
+}
+
+// release starts the release stage of the operators envelopes
+func (v *fmVoice) release() {
+	v.releasing.Store(true)
+}
+
+// envelope returns the current attack-decay-sustain-release gain of the operator, and advances its position
+func (op *fmOperator) envelope() float64 {
+	if op.releasePos >= 0 && op.release > 0 {
+		// fade out linearly from the gain at the key release
+		if op.releasePos >= op.release {
+			return 0
+		}
+		gain := op.gain * float64(op.release-op.releasePos) / float64(op.release)
+		op.releasePos++
+		return gain
+	}
+
+	pos := op.pos
+	if pos <= op.attack+op.decay {
+		op.pos++
+	}
+
+	switch {
+	case pos < op.attack:
+		op.gain = float64(pos) / float64(op.attack)
+	case pos < op.attack+op.decay:
+		op.gain = 1 - (1-op.sustain)*float64(pos-op.attack)/float64(op.decay)
+	default:
+		op.gain = op.sustain
+	}
+	return op.gain
+}
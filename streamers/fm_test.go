@@ -0,0 +1,85 @@
+package streamers
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// peak returns the peak of the next n samples of the voice
+func peak(v *fmVoice, n int) float64 {
+	samples := make([][2]float64, n)
+	v.Stream(samples)
+	res := 0.0
+	for _, sample := range samples {
+		res = max(res, math.Abs(sample[0]))
+	}
+	return res
+}
+
+func TestFMOperatorRelease(t *testing.T) {
+	tests := []struct {
+		name     string
+		release  time.Duration
+		wantPeak float64
+	}{
+		{name: "without release", release: 0, wantPeak: 1},
+		{name: "with release", release: 10 * time.Millisecond, wantPeak: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch := FMPatch{
+				Operators: []FMOperator{{Ratio: 1, Level: 1, Sustain: 1, Release: tt.release}, {Ratio: 1}},
+				Algorithm: FMStack,
+			}
+			streamer, err := newFMVoice(44100, 440, patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := streamer.(*fmVoice)
+			v.retrigger()
+			peak(v, 1000)
+			v.release()
+			// skip the release stage
+			peak(v, 441)
+			if got := peak(v, 1000); math.Abs(got-tt.wantPeak) > 0.01 {
+				t.Errorf("peak after release = %v, want %v", got, tt.wantPeak)
+			}
+
+			v.retrigger()
+			if got := peak(v, 1000); math.Abs(got-1) > 0.01 {
+				t.Errorf("peak after retrigger = %v, want 1", got)
+			}
+		})
+	}
+}
+
+func TestFMOperatorAboveNyquist(t *testing.T) {
+	tests := []struct {
+		name      string
+		ratio     float64
+		wantMuted bool
+	}{
+		{name: "below nyquist", ratio: 4},
+		{name: "above nyquist", ratio: 16, wantMuted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 2 carriers, each contributes half of the output
+			patch := FMPatch{
+				Operators: []FMOperator{{Ratio: 1, Level: 1, Sustain: 1}, {Ratio: tt.ratio, Level: 1, Sustain: 1}},
+				Algorithm: FMParallel,
+			}
+			streamer, err := newFMVoice(44100, 2000, patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := streamer.(*fmVoice)
+			v.retrigger()
+			// a muted operator leaves the half of the other carrier only
+			if got := peak(v, 44100); (got <= 0.51) != tt.wantMuted {
+				t.Errorf("peak = %v, muted operator expected: %v", got, tt.wantMuted)
+			}
+		})
+	}
+}
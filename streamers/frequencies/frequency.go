@@ -16,6 +16,7 @@ type Frequency interface {
 	MidiID() int
 	ShiftSemitone(semitonesDiff int) Frequency
 	ShiftOctave(octavesDiff int) Frequency
+	ShiftCents(cents float64) Frequency
 	fmt.Stringer
 }
 
@@ -25,6 +26,8 @@ type frequency struct {
 	midiId    int
 }
 
+// New returns the known frequency of freq, if there is one.
+// Otherwise, it is named by its nearest note, with the offset in cents (e.g. A4+2c).
 func New(freq float64) Frequency {
 	if i, ok := knownFrequencyIndexes[freq]; ok {
 		// return known frequency
@@ -32,7 +35,7 @@ func New(freq float64) Frequency {
 	}
 
 	return frequency{
-		name:      fmt.Sprintf("%vHz", freq),
+		name:      nearestName(freq),
 		frequency: freq,
 		midiId:    unknownMidiId,
 	}
@@ -60,10 +63,7 @@ func (f frequency) ShiftSemitone(semitonesDiff int) Frequency {
 	}
 
 	if i, ok := knownFrequencyIndexes[f.frequency]; ok {
-		newIndex := i + semitonesDiff
-		if newIndex >= 0 && newIndex < len(knownFrequencies) {
-			return knownFrequencies[newIndex]
-		}
+		return defaultTuning.Note(i + semitonesDiff)
 	}
 
 	return shiftSemitoneUnknownFreq(f.frequency, semitonesDiff)
@@ -73,6 +73,38 @@ func (f frequency) ShiftOctave(octavesDiff int) Frequency {
 	return f.ShiftSemitone(octavesDiff * 12)
 }
 
+func (f frequency) ShiftCents(cents float64) Frequency {
+	return shiftCents(f, cents)
+}
+
+func shiftCents(f Frequency, cents float64) Frequency {
+	if cents == 0 {
+		return f
+	}
+	return New(math.Round(f.Frequency()*math.Pow(2, cents/1200)*1e5) / 1e5)
+}
+
+// ShiftSemitoneFrequency returns the frequency of f.ShiftSemitone(semitonesDiff).
+// Unlike ShiftSemitone, the shifted note is not named, so it can be used while playing without allocations.
+func ShiftSemitoneFrequency(f Frequency, semitonesDiff int) float64 {
+	if semitonesDiff == 0 {
+		return f.Frequency()
+	}
+
+	switch f := f.(type) {
+	case tunedFrequency:
+		return f.tuning.noteFrequency(f.step + f.tuning.semitoneSteps(semitonesDiff))
+	case frequency:
+		if i, ok := knownFrequencyIndexes[f.frequency]; ok {
+			if j := i + semitonesDiff; j >= 0 && j < len(knownFrequencies) {
+				return knownFrequencies[j].Frequency()
+			}
+			return defaultTuning.Note(i + semitonesDiff).Frequency()
+		}
+	}
+	return f.Frequency() * math.Pow(semitoneMultiplier, float64(semitonesDiff))
+}
+
 func shiftSemitoneUnknownFreq(freq float64, semitonesDiff int) Frequency {
 	return New(freq * math.Pow(semitoneMultiplier, float64(semitonesDiff)))
 }
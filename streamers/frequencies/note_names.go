@@ -0,0 +1,85 @@
+package frequencies
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// e.g. C4, c#4, D♭-1, C♯/D♭4, A4+15c, A4-3.5¢
+	noteNameRegexp = regexp.MustCompile(`^([a-gA-G])([#♯b♭]?)(?:/[a-gA-G][#♯b♭]?)?(-?\d+)(?:([+-]\d+(?:\.\d+)?)[c¢])?$`)
+	// e.g. 440Hz, 261.63hz
+	hertzRegexp    = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*[hH][zZ]$`)
+	letterSemitone = map[string]int{"c": 0, "d": 2, "e": 4, "f": 5, "g": 7, "a": 9, "b": 11}
+)
+
+// Parse parses a note name with an optional cents offset (e.g. C#4, Bb-1, A4+15c), or a frequency in Hz (e.g. 440.5Hz).
+// Both # & ♯ are accepted as sharps, and both b & ♭ as flats.
+func Parse(name string) (Frequency, error) {
+	name = strings.TrimSpace(name)
+	if match := hertzRegexp.FindStringSubmatch(name); match != nil {
+		freq, _ := strconv.ParseFloat(match[1], 64)
+		return New(freq), nil
+	}
+
+	match := noteNameRegexp.FindStringSubmatch(name)
+	if match == nil {
+		return nil, fmt.Errorf("note %q is invalid", name)
+	}
+
+	octave, err := strconv.Atoi(match[3])
+	if err != nil {
+		return nil, fmt.Errorf("note %q is invalid", name)
+	}
+	midiId := (octave+1)*12 + letterSemitone[strings.ToLower(match[1])]
+	switch match[2] {
+	case "#", "♯":
+		midiId++
+	case "b", "♭":
+		midiId--
+	}
+
+	freq := FromMidi(midiId)
+	if match[4] != "" {
+		cents, _ := strconv.ParseFloat(match[4], 64)
+		freq = freq.ShiftCents(cents)
+	}
+	return freq, nil
+}
+
+// FromMidi returns the note of the midi id, in 12 tone equal temperament with A4=440Hz (e.g. 60 is C4)
+func FromMidi(midiId int) Frequency {
+	return defaultTuning.Note(midiId)
+}
+
+// Nearest returns the note closest to freq in 12 tone equal temperament with A4=440Hz, and the offset of freq from it in cents
+func Nearest(freq float64) (note Frequency, cents float64) {
+	note = FromMidi(NearestStep(freq))
+	return note, 1200 * math.Log2(freq/note.Frequency())
+}
+
+// NearestStep returns the step of the note closest to freq in 12 tone equal temperament with A4=440Hz, where C-1 is step 0.
+// It equals the MIDI id within the MIDI range, and continues beyond it, where notes have no MIDI id.
+func NearestStep(freq float64) int {
+	return int(math.Round(float64(referenceMidiId()) + 12*math.Log2(freq/DefaultReference)))
+}
+
+func referenceMidiId() int {
+	return (referenceOctave+1)*12 + referenceSemitone
+}
+
+// nearestName names freq by its nearest note, and its offset in cents (e.g. A4+15c)
+func nearestName(freq float64) string {
+	if freq <= 0 {
+		return fmt.Sprintf("%vHz", freq)
+	}
+
+	note, cents := Nearest(freq)
+	if cents := math.Round(cents); cents != 0 {
+		return fmt.Sprintf("%v%+.0fc", note.Name(), cents)
+	}
+	return note.Name()
+}
@@ -0,0 +1,78 @@
+package frequencies
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    float64
+		wantErr bool
+	}{
+		{name: "A4", want: 440},
+		{name: "a4", want: 440},
+		{name: " A4 ", want: 440},
+		{name: "C4", want: 261.63},
+		{name: "C#4", want: 277.18},
+		{name: "C♯4", want: 277.18},
+		{name: "Db4", want: 277.18},
+		{name: "D♭4", want: 277.18},
+		{name: "C♯/D♭4", want: 277.18},
+		{name: "Cb4", want: 246.94},
+		{name: "B#3", want: 261.63},
+		{name: "C-1", want: 8.18},
+		{name: "G9", want: 12543.85},
+		{name: "G#9", want: 13289.75},
+		{name: "B#9", want: 16744.04},
+		{name: "A4+50c", want: 440 * math.Pow(2, 50.0/1200)},
+		{name: "A4-1200c", want: 220},
+		{name: "A4+15.5¢", want: 440 * math.Pow(2, 15.5/1200)},
+		{name: "440Hz", want: 440},
+		{name: "261.63 hz", want: 261.63},
+		{name: "", wantErr: true},
+		{name: "H4", wantErr: true},
+		{name: "A", wantErr: true},
+		{name: "A4+50", wantErr: true},
+		{name: "A#b4", wantErr: true},
+		{name: "-440Hz", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			freq, err := Parse(tt.name)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Parse(%q) = %v, want an error", tt.name, freq)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error %v", tt.name, err)
+			}
+			if got := freq.Frequency(); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("Parse(%q) = %vHz, want %vHz", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNearestStep(t *testing.T) {
+	tests := []struct {
+		freq float64
+		want int
+	}{
+		{freq: 440, want: 69},
+		{freq: 8.18, want: 0},
+		{freq: 12543.85, want: 127},
+		{freq: 13289.75, want: 128},
+		{freq: 4, want: -12},
+		{freq: 452, want: 69},
+		{freq: 453.5, want: 70},
+	}
+	for _, tt := range tests {
+		if got := NearestStep(tt.freq); got != tt.want {
+			t.Errorf("NearestStep(%v) = %v, want %v", tt.freq, got, tt.want)
+		}
+	}
+}
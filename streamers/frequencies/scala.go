@@ -0,0 +1,193 @@
+package frequencies
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Scala file formats, see https://www.huygens-fokker.org/scala/scl_format.html & https://www.huygens-fokker.org/scala/help.htm#mappings
+
+// LoadScalaTuning loads a Scala scale (.scl) file, and an optional keyboard mapping (.kbm) file.
+// Without a keyboard mapping, each step is mapped to the next degree of the scale, starting at C-1,
+// and the degree closest to A is tuned to A4=440Hz.
+func LoadScalaTuning(sclPath, kbmPath string) (Tuning, error) {
+	t, err := loadScalaScale(sclPath)
+	if err != nil {
+		return nil, err
+	}
+	if kbmPath == "" {
+		return t, nil
+	}
+
+	if err = t.loadKeyboardMapping(kbmPath); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func loadScalaScale(path string) (tuning, error) {
+	lines, err := readScalaLines(path)
+	if err != nil {
+		return tuning{}, err
+	}
+	// the first line is the description, which may be empty
+	if len(lines) > 0 {
+		lines = append(lines[:1], nonEmpty(lines[1:])...)
+	}
+	if len(lines) < 2 {
+		return tuning{}, fmt.Errorf("%v: missing notes count", filepath.Base(path))
+	}
+
+	count, err := strconv.Atoi(firstField(lines[1]))
+	if err != nil || count < 1 {
+		return tuning{}, fmt.Errorf("%v: illegal notes count %q", filepath.Base(path), lines[1])
+	}
+	if len(lines)-2 < count {
+		return tuning{}, fmt.Errorf("%v: expected %v notes, found %v", filepath.Base(path), count, len(lines)-2)
+	}
+
+	// the unison (1/1) is implicit, and the last note is the period
+	ratios := make([]float64, count)
+	names := make([]string, count)
+	ratios[0], names[0] = 1, "1/1"
+	var period float64
+	for i, line := range lines[2 : 2+count] {
+		name := firstField(line)
+		ratio, err := parseScalaPitch(name)
+		if err != nil {
+			return tuning{}, fmt.Errorf("%v: %w", filepath.Base(path), err)
+		}
+		if i == count-1 {
+			period = ratio
+		} else {
+			ratios[i+1], names[i+1] = ratio, name
+		}
+	}
+	if period <= 1 {
+		return tuning{}, fmt.Errorf("%v: the last note should be above 1/1", filepath.Base(path))
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return newTuning(name, ratios, period, names), nil
+}
+
+// parseScalaPitch parses either cents (with a period, e.g. 386.3137), or a ratio (e.g. 5/4 or 2)
+func parseScalaPitch(pitch string) (float64, error) {
+	if strings.Contains(pitch, ".") {
+		cents, err := strconv.ParseFloat(pitch, 64)
+		if err != nil {
+			return 0, fmt.Errorf("illegal cents %q", pitch)
+		}
+		return math.Pow(2, cents/1200), nil
+	}
+
+	numerator, denominator, isRatio := strings.Cut(pitch, "/")
+	n, err := strconv.ParseUint(numerator, 10, 64)
+	d := uint64(1)
+	if err == nil && isRatio {
+		d, err = strconv.ParseUint(denominator, 10, 64)
+	}
+	if err != nil || n == 0 || d == 0 {
+		return 0, fmt.Errorf("illegal ratio %q", pitch)
+	}
+	return float64(n) / float64(d), nil
+}
+
+// loadKeyboardMapping maps the steps as midi notes, according to the .kbm file
+func (t *tuning) loadKeyboardMapping(path string) error {
+	lines, err := readScalaLines(path)
+	if err != nil {
+		return err
+	}
+	lines = nonEmpty(lines)
+	if len(lines) < 7 {
+		return fmt.Errorf("%v: expected at least 7 lines, found %v", filepath.Base(path), len(lines))
+	}
+
+	header := make([]float64, 7)
+	for i := range header {
+		if header[i], err = strconv.ParseFloat(firstField(lines[i]), 64); err != nil {
+			return fmt.Errorf("%v: illegal value %q in line %v", filepath.Base(path), lines[i], i+1)
+		}
+	}
+	// the first & last midi notes to retune (header[1] & header[2]) are ignored, all notes are retuned
+	size, middle, referenceNote, reference, periodDegrees := int(header[0]), int(header[3]), int(header[4]), header[5], int(header[6])
+	if size < 0 || reference <= 0 || periodDegrees < 0 {
+		return fmt.Errorf("%v: illegal map size or reference frequency", filepath.Base(path))
+	}
+
+	mapping := make([]int, size)
+	for i := range mapping {
+		// missing entries are unmapped
+		mapping[i] = -1
+		if 7+i >= len(lines) {
+			continue
+		}
+		if entry := firstField(lines[7+i]); entry != "x" {
+			if mapping[i], err = strconv.Atoi(entry); err != nil || mapping[i] < 0 {
+				return fmt.Errorf("%v: illegal mapping entry %q", filepath.Base(path), entry)
+			}
+		}
+	}
+	// a zero size maps each note to the next degree
+	if size == 0 {
+		mapping = t.mapping
+	}
+	if size == 0 || periodDegrees == 0 {
+		periodDegrees = len(t.ratios)
+	}
+
+	t.name += "+" + strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	t.mapping = mapping
+	t.periodDegrees = periodDegrees
+	t.middleStep = middle
+	t.isMidi = true
+	t.reference = reference
+	t.referenceStep = referenceNote
+	if _, ok := t.degree(referenceNote); !ok {
+		return fmt.Errorf("%v: the reference note is unmapped", filepath.Base(path))
+	}
+	return nil
+}
+
+// readScalaLines returns the lines of the file, without comments (lines starting with !)
+func readScalaLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); !strings.HasPrefix(line, "!") {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+func nonEmpty(lines []string) []string {
+	res := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line != "" {
+			res = append(res, line)
+		}
+	}
+	return res
+}
+
+// firstField returns the first whitespace separated field of line, ignoring any following text
+func firstField(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
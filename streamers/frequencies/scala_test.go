@@ -0,0 +1,224 @@
+package frequencies
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	// 5 limit just intonation major scale
+	justMajorScl = `! just_major.scl
+!
+Just intonation major scale
+ 7
+!
+ 9/8
+ 5/4
+ 4/3
+ 3/2
+ 5/3
+ 15/8
+ 2/1
+`
+	// 12 tone equal temperament in cents, with trailing text
+	twelveToneScl = `12 tone equal temperament
+12
+100.0 minor second
+200.0
+300.0
+400.0
+500.0
+600.0
+700.0
+800.0
+900.0
+1000.0
+1100.0
+1200.0 octave
+`
+	// the white keys are mapped to the just major scale, the black keys are unmapped. C4 is the first degree, A4 is 432Hz
+	whiteKeysKbm = `! white_keys.kbm
+12
+0
+127
+60
+69
+432.0
+7
+! mapping
+0
+x
+1
+x
+2
+3
+x
+4
+x
+5
+x
+6
+`
+)
+
+func TestLoadScalaTuning(t *testing.T) {
+	tests := []struct {
+		name      string
+		scl, kbm  string
+		divisions int
+		// expected frequencies by step
+		notes map[int]float64
+	}{
+		{
+			name:      "scale",
+			scl:       justMajorScl,
+			divisions: 7,
+			// without a mapping, the degree closest to A (the 6th, 5/3) is tuned to 440Hz, in octave 4
+			notes: map[int]float64{7*5 + 5: 440, 7*5 + 4: 440 * 9 / 10, 7*5 + 7: 440 * 6 / 5, 7*4 + 5: 220},
+		},
+		{
+			name:      "cents",
+			scl:       twelveToneScl,
+			divisions: 12,
+			notes:     map[int]float64{69: 440, 60: 261.62556, 81: 880},
+		},
+		{
+			name:      "keyboard mapping",
+			scl:       justMajorScl,
+			kbm:       whiteKeysKbm,
+			divisions: 12,
+			// black keys are unmapped, and silent
+			notes: map[int]float64{69: 432, 60: 259.2, 64: 324, 67: 388.8, 72: 518.4, 48: 129.6, 61: 0, 70: 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sclPath := writeTestFile(t, "test.scl", tt.scl)
+			kbmPath := ""
+			if tt.kbm != "" {
+				kbmPath = writeTestFile(t, "test.kbm", tt.kbm)
+			}
+
+			tuning, err := LoadScalaTuning(sclPath, kbmPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tuning.Divisions(); got != tt.divisions {
+				t.Errorf("Divisions() = %v, want %v", got, tt.divisions)
+			}
+			for step, want := range tt.notes {
+				if got := tuning.Note(step).Frequency(); math.Abs(got-want) > 0.001 {
+					t.Errorf("Note(%v) = %vHz, want %vHz", step, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestScalaTuningEquals(t *testing.T) {
+	sclPath := writeTestFile(t, "test.scl", justMajorScl)
+	kbmPath := writeTestFile(t, "test.kbm", whiteKeysKbm)
+	scale, err := LoadScalaTuning(sclPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := LoadScalaTuning(sclPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapped, err := LoadScalaTuning(sclPath, kbmPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mappedToScaleReference, err := mapped.WithReference(scale.Reference())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !scale.Equals(reloaded) {
+		t.Errorf("reloaded tuning is not equal to the original")
+	}
+	// same name & reference, different keyboard mapping
+	if scale.Equals(mappedToScaleReference) || mappedToScaleReference.Equals(scale) {
+		t.Errorf("tunings of different keyboard mappings are equal")
+	}
+	if scale.Equals(nil) {
+		t.Errorf("tuning is equal to nil")
+	}
+}
+
+func TestLoadScalaTuningErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		scl, kbm string
+	}{
+		{name: "empty", scl: ""},
+		{name: "missing count", scl: "description\n"},
+		{name: "illegal count", scl: "description\nseven\n9/8\n"},
+		{name: "missing notes", scl: "description\n3\n9/8\n5/4\n"},
+		{name: "illegal ratio", scl: "description\n2\n9/0\n2/1\n"},
+		{name: "illegal cents", scl: "description\n2\n1.2.3\n2/1\n"},
+		{name: "period below unison", scl: "description\n1\n1/2\n"},
+		{name: "short mapping", scl: justMajorScl, kbm: "12\n0\n127\n60\n69\n"},
+		{name: "illegal reference", scl: justMajorScl, kbm: "12\n0\n127\n60\n69\n0\n7\n"},
+		{name: "illegal mapping entry", scl: justMajorScl, kbm: "1\n0\n127\n60\n60\n440\n7\ny\n"},
+		{name: "unmapped reference", scl: justMajorScl, kbm: "2\n0\n127\n60\n61\n440\n7\n0\nx\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sclPath := writeTestFile(t, "test.scl", tt.scl)
+			kbmPath := ""
+			if tt.kbm != "" {
+				kbmPath = writeTestFile(t, "test.kbm", tt.kbm)
+			}
+
+			if _, err := LoadScalaTuning(sclPath, kbmPath); err == nil {
+				t.Error("LoadScalaTuning() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestParseScalaPitch(t *testing.T) {
+	tests := []struct {
+		pitch   string
+		want    float64
+		wantErr bool
+	}{
+		{pitch: "3/2", want: 1.5},
+		{pitch: "2", want: 2},
+		{pitch: "1200.0", want: 2},
+		{pitch: "701.955", want: 1.5},
+		{pitch: "-1200.0", want: 0.5},
+		{pitch: "0/1", wantErr: true},
+		{pitch: "3/0", wantErr: true},
+		{pitch: "-3/2", wantErr: true},
+		{pitch: "a/b", wantErr: true},
+		{pitch: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseScalaPitch(tt.pitch)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseScalaPitch(%q) = %v, want an error", tt.pitch, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseScalaPitch(%q) returned error %v", tt.pitch, err)
+		} else if math.Abs(got-tt.want) > 1e-5 {
+			t.Errorf("parseScalaPitch(%q) = %v, want %v", tt.pitch, got, tt.want)
+		}
+	}
+}
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
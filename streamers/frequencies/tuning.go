@@ -0,0 +1,312 @@
+package frequencies
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+const (
+	DefaultReference = 440.0
+
+	// A4 is the 9th semitone of the 4th octave, where octave -1 starts at step 0
+	referenceOctave   = 4
+	referenceSemitone = 9
+)
+
+var (
+	noteNames     = []string{`C`, `C♯/D♭`, `D`, `E♭/D♯`, `E`, `F`, `F♯/G♭`, `G`, `A♭/G♯`, `A`, `B♭/A♯`, `B`}
+	defaultTuning = mustEqualTemperament(12)
+)
+
+// Tuning sets the frequencies of the notes.
+// The notes are numbered in steps, where step 0 is the first note of octave -1, and each octave is Divisions() steps long.
+// In 12 notes tunings, the steps are the midi ids.
+type Tuning interface {
+	Name() string
+	// Divisions returns the number of notes per octave
+	Divisions() int
+	// Reference returns the frequency of the reference note (A4, unless set otherwise by a keyboard mapping), which all other notes are tuned to
+	Reference() float64
+	WithReference(reference float64) (Tuning, error)
+	Note(step int) Frequency
+	Equals(other Tuning) bool
+	fmt.Stringer
+}
+
+type tuning struct {
+	name string
+	// ratios of each degree of the scale to its first degree, starting with 1
+	ratios []float64
+	// ratio of the scale repetition, usually an octave (2)
+	period      float64
+	degreeNames []string
+	nameFormat  string
+
+	// keyboard mapping: each step is mapped to a scale degree, or to -1 if the step is unmapped.
+	// The mapping repeats itself every len(mapping) steps, shifted by periodDegrees degrees.
+	mapping       []int
+	periodDegrees int
+	// step mapped to mapping[0]
+	middleStep int
+	// steps are midi notes, regardless of the number of divisions
+	isMidi bool
+
+	reference float64
+	// step of the note tuned to the reference frequency
+	referenceStep int
+}
+
+// DefaultTuning is the 12 tone equal temperament, with A4=440Hz
+func DefaultTuning() Tuning {
+	return defaultTuning
+}
+
+// EqualTemperament divides the octave into equal steps.
+// The reference is set to the step closest to A4 in 12 tone equal temperament.
+func EqualTemperament(divisions int) (Tuning, error) {
+	if divisions < 1 {
+		return nil, fmt.Errorf("divisions should be positive")
+	}
+
+	ratios := make([]float64, divisions)
+	names := make([]string, divisions)
+	for i := range ratios {
+		ratios[i] = math.Pow(2, float64(i)/float64(divisions))
+		names[i] = fmt.Sprintf("%v\\%v", i, divisions)
+	}
+	if divisions == len(noteNames) {
+		return newTwelveNotesTuning("12-TET", ratios), nil
+	}
+	return newTuning(fmt.Sprintf("%v-TET", divisions), ratios, 2, names), nil
+}
+
+// JustIntonation is the 5-limit just intonation of C major
+func JustIntonation() Tuning {
+	ratios := []float64{1, 16.0 / 15, 9.0 / 8, 6.0 / 5, 5.0 / 4, 4.0 / 3, 45.0 / 32, 3.0 / 2, 8.0 / 5, 5.0 / 3, 9.0 / 5, 15.0 / 8}
+	return newTwelveNotesTuning("just", ratios)
+}
+
+// Pythagorean stacks pure fifths (3:2), from E♭ to G♯
+func Pythagorean() Tuning {
+	ratios := []float64{1, 256.0 / 243, 9.0 / 8, 32.0 / 27, 81.0 / 64, 4.0 / 3, 729.0 / 512, 3.0 / 2, 128.0 / 81, 27.0 / 16, 16.0 / 9, 243.0 / 128}
+	return newTwelveNotesTuning("pythag", ratios)
+}
+
+// Meantone is the quarter-comma meantone, which narrows the fifths to make the major thirds pure (5:4)
+func Meantone() Tuning {
+	// fifth = 5^(1/4)
+	ratios := []float64{1, math.Pow(5, 7.0/4) / 16, math.Sqrt(5) / 2, 4 / math.Pow(5, 3.0/4), 5.0 / 4, 2 / math.Pow(5, 1.0/4),
+		math.Pow(5, 3.0/2) / 8, math.Pow(5, 1.0/4), 25.0 / 16, math.Pow(5, 3.0/4) / 2, 4 / math.Sqrt(5), math.Pow(5, 5.0/4) / 4}
+	return newTwelveNotesTuning("meantone", ratios)
+}
+
+// Tunings returns the built-in tunings, with A4=440Hz
+func Tunings() []Tuning {
+	tunings := make([]Tuning, 0, 7)
+	for _, divisions := range []int{12, 19, 24, 31} {
+		tunings = append(tunings, mustEqualTemperament(divisions))
+	}
+	return append(tunings, JustIntonation(), Pythagorean(), Meantone())
+}
+
+func mustEqualTemperament(divisions int) Tuning {
+	t, err := EqualTemperament(divisions)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func newTwelveNotesTuning(name string, ratios []float64) tuning {
+	t := newTuning(name, ratios, 2, noteNames)
+	t.nameFormat = "%v%v"
+	t.isMidi = true
+	return t
+}
+
+// newTuning maps each step to the next degree of the scale, where step 0 is the first degree of octave -1.
+// A4 is tuned to the reference, and is set to the degree closest to 9/12 of the period.
+func newTuning(name string, ratios []float64, period float64, degreeNames []string) tuning {
+	// distance of ratio from A, in twelfths of the period
+	distance := func(ratio float64) float64 {
+		return math.Abs(math.Log(ratio)/math.Log(period)*12 - referenceSemitone)
+	}
+
+	mapping := make([]int, len(ratios))
+	referenceDegree := 0
+	for i := range mapping {
+		mapping[i] = i
+		if distance(ratios[i]) < distance(ratios[referenceDegree]) {
+			referenceDegree = i
+		}
+	}
+
+	return tuning{
+		name:          name,
+		ratios:        ratios,
+		period:        period,
+		degreeNames:   degreeNames,
+		nameFormat:    "%v o%v",
+		mapping:       mapping,
+		periodDegrees: len(ratios),
+		reference:     DefaultReference,
+		referenceStep: (referenceOctave+1)*len(ratios) + referenceDegree,
+	}
+}
+
+func (t tuning) Name() string {
+	return t.name
+}
+
+func (t tuning) String() string {
+	return t.name
+}
+
+func (t tuning) Divisions() int {
+	return len(t.mapping)
+}
+
+func (t tuning) Reference() float64 {
+	return t.reference
+}
+
+func (t tuning) WithReference(reference float64) (Tuning, error) {
+	if reference <= 0 {
+		return nil, fmt.Errorf("reference frequency should be positive")
+	}
+	t.reference = reference
+	return t, nil
+}
+
+// Equals returns true if other tunes every step to the same note as t
+func (t tuning) Equals(other Tuning) bool {
+	o, ok := other.(tuning)
+	return ok && t.name == o.name && t.reference == o.reference && t.referenceStep == o.referenceStep &&
+		t.period == o.period && slices.Equal(t.ratios, o.ratios) &&
+		t.nameFormat == o.nameFormat && slices.Equal(t.degreeNames, o.degreeNames) &&
+		t.periodDegrees == o.periodDegrees && t.middleStep == o.middleStep && t.isMidi == o.isMidi &&
+		slices.Equal(t.mapping, o.mapping)
+}
+
+// Note returns the note of step, or Silence if a keyboard mapping leaves step unmapped
+func (t tuning) Note(step int) Frequency {
+	if t.isDefault() && step >= 0 && step < len(knownFrequencies) {
+		return knownFrequencies[step]
+	}
+	if _, ok := t.degree(step); !ok {
+		return Silence()
+	}
+
+	return tunedFrequency{
+		name:      t.noteName(step),
+		frequency: t.noteFrequency(step),
+		step:      step,
+		tuning:    t,
+	}
+}
+
+// noteFrequency returns the frequency of the note of step, without naming it
+func (t tuning) noteFrequency(step int) float64 {
+	if t.isDefault() && step >= 0 && step < len(knownFrequencies) {
+		return knownFrequencies[step].Frequency()
+	}
+	return math.Round(t.frequency(step)*1e5) / 1e5
+}
+
+// semitoneSteps returns the number of steps which is the closest to semitonesDiff 12 tone semitones
+func (t tuning) semitoneSteps(semitonesDiff int) int {
+	return int(math.Round(float64(semitonesDiff*t.Divisions()) / 12))
+}
+
+// isDefault returns true for the 12 tone equal temperament with A4=440Hz, which is the tuning of the known frequencies
+func (t tuning) isDefault() bool {
+	return t.Equals(defaultTuning)
+}
+
+// frequency returns the frequency of step, or 0 if step is unmapped
+func (t tuning) frequency(step int) float64 {
+	degree, ok := t.degree(step)
+	if !ok {
+		return 0
+	}
+	refDegree, _ := t.degree(t.referenceStep)
+	return t.reference * t.pitch(degree) / t.pitch(refDegree)
+}
+
+// degree returns the scale degree of step, counted from the first degree at the middle step
+func (t tuning) degree(step int) (int, bool) {
+	repetition, i := floorDivMod(step-t.middleStep, len(t.mapping))
+	if t.mapping[i] < 0 {
+		return 0, false
+	}
+	return t.mapping[i] + repetition*t.periodDegrees, true
+}
+
+// pitch returns the ratio of degree to the first degree
+func (t tuning) pitch(degree int) float64 {
+	period, i := floorDivMod(degree, len(t.ratios))
+	return math.Pow(t.period, float64(period)) * t.ratios[i]
+}
+
+// noteName returns the name of the scale degree of the mapped step, and its octave, where the middle step is the first degree of its octave
+func (t tuning) noteName(step int) string {
+	degree, _ := t.degree(step)
+	middleOctave, _ := floorDivMod(t.middleStep, len(t.mapping))
+	octave, i := floorDivMod(degree, len(t.ratios))
+	return fmt.Sprintf(t.nameFormat, t.degreeNames[i], octave+middleOctave-1)
+}
+
+// floorDivMod returns the floored quotient and the non-negative remainder of a/b
+func floorDivMod(a, b int) (quotient, remainder int) {
+	quotient, remainder = a/b, a%b
+	if remainder < 0 {
+		quotient--
+		remainder += b
+	}
+	return quotient, remainder
+}
+
+// tunedFrequency is a note of a tuning
+type tunedFrequency struct {
+	name      string
+	frequency float64
+	step      int
+	tuning    tuning
+}
+
+func (f tunedFrequency) Name() string {
+	return f.name
+}
+
+func (f tunedFrequency) Frequency() float64 {
+	return f.frequency
+}
+
+func (f tunedFrequency) MidiID() int {
+	if f.tuning.isMidi {
+		return f.step
+	}
+	return unknownMidiId
+}
+
+func (f tunedFrequency) String() string {
+	return f.name
+}
+
+// ShiftSemitone shifts to the note of the tuning which is the closest to semitonesDiff 12 tone semitones
+func (f tunedFrequency) ShiftSemitone(semitonesDiff int) Frequency {
+	if semitonesDiff == 0 {
+		return f
+	}
+	return f.tuning.Note(f.step + f.tuning.semitoneSteps(semitonesDiff))
+}
+
+// ShiftCents returns a frequency outside of the tuning, named by its nearest 12 tone note
+func (f tunedFrequency) ShiftCents(cents float64) Frequency {
+	return shiftCents(f, cents)
+}
+
+func (f tunedFrequency) ShiftOctave(octavesDiff int) Frequency {
+	return f.tuning.Note(f.step + octavesDiff*f.tuning.Divisions())
+}
@@ -0,0 +1,73 @@
+package streamers
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/HuBeZa/synth/streamers/composers"
+)
+
+// glidable is implemented by tone sources that can slide to a new frequency (portamento)
+type glidable interface {
+	glide(freq float64, length int, transitionType composers.TransitionType) error
+}
+
+// glidingParam is a frequency parameter that can either be smoothed to its target (see smoothedParam),
+// or slide to it exponentially (in the pitch domain) within a given length.
+type glidingParam struct {
+	smoothedParam
+
+	// glide request, set by any goroutine. seq is incremented after the other fields are set.
+	glideLength     atomic.Int64
+	glideTransition atomic.Int64
+	glideSeq        atomic.Uint64
+
+	// glide state, owned by the streaming goroutine
+	seq            uint64
+	pos            int
+	length         int
+	from           float64
+	transitionFunc composers.TransitionFunc
+}
+
+// glideTo slides the parameter from its current value to freq. The shape of the slide is set by transitionType.
+func (p *glidingParam) glideTo(freq float64, length int, transitionType composers.TransitionType) {
+	p.SetTarget(freq)
+	p.glideLength.Store(int64(length))
+	p.glideTransition.Store(int64(transitionType))
+	p.glideSeq.Add(1)
+}
+
+// next advances the glide (or the smoothing ramp) by a single sample, and returns the current value
+func (p *glidingParam) next() float64 {
+	if seq := p.glideSeq.Load(); seq != p.seq {
+		p.seq = seq
+		p.pos = 0
+		p.length = int(p.glideLength.Load())
+		p.from = p.current
+		p.transitionFunc = composers.TransitionType(p.glideTransition.Load()).Func()
+		if p.transitionFunc == nil || p.from <= 0 {
+			// cannot glide from silence, jump to target
+			p.length = 0
+			p.current = p.Target()
+		}
+	}
+
+	if p.pos < p.length {
+		p.pos++
+		target := p.Target()
+		progress := p.transitionFunc(float64(p.pos) / float64(p.length))
+		p.current = p.from * math.Pow(target/p.from, progress)
+		return p.current
+	}
+
+	return p.smoothedParam.next()
+}
+
+// snap jumps to the target without smoothing, unless a glide is pending or in progress.
+// It should be called by the streaming goroutine only.
+func (p *glidingParam) snap() {
+	if p.glideSeq.Load() == p.seq && p.pos >= p.length {
+		p.current = p.Target()
+	}
+}
@@ -0,0 +1,282 @@
+package streamers
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/gopxl/beep/v2/effects"
+
+	"github.com/HuBeZa/synth/streamers/composers"
+)
+
+const (
+	// GranularBufferTime is the length of the rolling buffer, recorded from a granular input
+	GranularBufferTime = 4 * time.Second
+	MaxGrains          = 64
+
+	// seed of the grains jitter, so renders are deterministic
+	granularSeed = 1
+)
+
+// Granular plays overlapping, windowed grains of a source - either a sample, or a rolling buffer recorded from an input.
+// The input is consumed by the granular streamer, so it should not be played by any other streamer.
+type Granular interface {
+	beep.Streamer
+	IsSilenced() bool
+	Silence()
+	Unsilence()
+	ToggleSilence()
+	Sample() *Sample
+	SetSample(sample *Sample)
+	Input() beep.Streamer
+	SetInput(input beep.Streamer)
+	Grains() GrainParams
+	SetGrains(params GrainParams) error
+}
+
+// GrainParams sets the grains creation
+type GrainParams struct {
+	Size time.Duration
+	// grains per second
+	Density float64
+	// start position of the grains, 0 (source start / oldest buffered audio) to 1 (source end / newest buffered audio)
+	Position float64
+	// random offset of the start position, 0 to 1, added to & subtracted from the position
+	PositionJitter float64
+	// random pitch change of the grains, in cents, up & down
+	PitchJitter float64
+	// shape of the grain window's fade in & fade out
+	Window composers.TransitionType
+}
+
+func DefaultGrainParams() GrainParams {
+	return GrainParams{
+		Size:     100 * time.Millisecond,
+		Density:  20,
+		Position: 0.5,
+		Window:   composers.EqualPower,
+	}
+}
+
+func (p GrainParams) validate() error {
+	if p.Size <= 0 {
+		return fmt.Errorf("grain size should be positive")
+	}
+	if p.Density <= 0 {
+		return fmt.Errorf("grains density should be positive")
+	}
+	if p.Position < 0 || p.Position > 1 {
+		return fmt.Errorf("grains position should be between 0 to 1")
+	}
+	if p.PositionJitter < 0 || p.PositionJitter > 1 {
+		return fmt.Errorf("grains position jitter should be between 0 to 1")
+	}
+	if p.PitchJitter < 0 {
+		return fmt.Errorf("grains pitch jitter should not be negative")
+	}
+	if p.Window.Func() == nil {
+		return fmt.Errorf("grain window unknown")
+	}
+	return nil
+}
+
+type grain struct {
+	// position in source frames
+	pos float64
+	// source frames per output frame
+	increment float64
+	age       int
+	length    int
+}
+
+type granular struct {
+	sampleRate beep.SampleRate
+	sample     atomic.Pointer[Sample]
+	input      atomic.Pointer[beep.Streamer]
+	params     atomic.Pointer[GrainParams]
+	silenced   atomic.Bool
+	rng        *rand.Rand
+
+	// streaming state, owned by the streaming goroutine
+	buffer      [][2]float64
+	bufferHead  int
+	inputBuffer [][2]float64
+	grains      []grain
+	nextGrain   int
+}
+
+func NewGranular(sampleRate beep.SampleRate, params GrainParams) (Granular, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
+	g := &granular{
+		sampleRate: sampleRate,
+		rng:        rand.New(rand.NewPCG(granularSeed, 0)),
+		buffer:     make([][2]float64, sampleRate.N(GranularBufferTime)),
+		grains:     make([]grain, 0, MaxGrains),
+	}
+	g.params.Store(&params)
+	return g, nil
+}
+
+func (g *granular) Stream(samples [][2]float64) (n int, ok bool) {
+	if g.IsSilenced() {
+		return silenceStreamer.Stream(samples)
+	}
+
+	input, sample := g.Input(), g.Sample()
+	if input != nil {
+		g.record(input, len(samples))
+	} else if sample == nil {
+		return silenceStreamer.Stream(samples)
+	}
+
+	// source frames accessor & length, and the speed of the source relative to the output
+	frame, length, rate := g.bufferFrame, len(g.buffer), 1.0
+	if input == nil {
+		frame, length, rate = sample.frame, sample.Len(), float64(sample.sampleRate)/float64(g.sampleRate)
+	}
+
+	params := g.Grains()
+	window := composers.TransitionLoop(effects.TransitionFunc(params.Window.Func()))
+	grainLength := max(g.sampleRate.N(params.Size), 1)
+	// normalize the sum of overlapping grains
+	gain := 1 / math.Sqrt(max(params.Density*params.Size.Seconds(), 1))
+
+	for i := range samples {
+		if g.nextGrain <= 0 {
+			g.addGrain(params, length, rate, grainLength)
+			g.nextGrain = max(int(float64(g.sampleRate)/params.Density), 1)
+		}
+		g.nextGrain--
+
+		var v [2]float64
+		for j := range g.grains {
+			gr := &g.grains[j]
+			w := window(float64(gr.age) / float64(gr.length))
+			f := frame(math.Mod(gr.pos, float64(length)))
+			v[0] += w * f[0]
+			v[1] += w * f[1]
+			gr.pos += gr.increment
+			gr.age++
+		}
+		samples[i][0] = gain * v[0]
+		samples[i][1] = gain * v[1]
+
+		g.removeEndedGrains()
+	}
+
+	return len(samples), true
+}
+
+func (g *granular) addGrain(params GrainParams, length int, rate float64, grainLength int) {
+	if len(g.grains) == MaxGrains {
+		return
+	}
+
+	position := params.Position + params.PositionJitter*(2*g.rng.Float64()-1)
+	position = min(max(position, 0), 1)
+	cents := params.PitchJitter * (2*g.rng.Float64() - 1)
+
+	g.grains = append(g.grains, grain{
+		pos:       position * float64(length-1),
+		increment: rate * math.Pow(2, cents/1200),
+		length:    grainLength,
+	})
+}
+
+func (g *granular) removeEndedGrains() {
+	grains := g.grains[:0]
+	for _, gr := range g.grains {
+		if gr.age < gr.length {
+			grains = append(grains, gr)
+		}
+	}
+	g.grains = grains
+}
+
+// record streams n samples from the input into the rolling buffer
+func (g *granular) record(input beep.Streamer, n int) {
+	if cap(g.inputBuffer) < n {
+		g.inputBuffer = make([][2]float64, n)
+	}
+	buf := g.inputBuffer[:n]
+	read, _ := input.Stream(buf)
+	clear(buf[read:])
+
+	for _, sample := range buf {
+		g.buffer[g.bufferHead] = sample
+		g.bufferHead = (g.bufferHead + 1) % len(g.buffer)
+	}
+}
+
+// bufferFrame returns the frame at pos of the rolling buffer, where 0 is the oldest frame
+func (g *granular) bufferFrame(pos float64) [2]float64 {
+	i := int(pos)
+	frac := pos - float64(i)
+	curr := g.buffer[(g.bufferHead+i)%len(g.buffer)]
+	next := g.buffer[(g.bufferHead+i+1)%len(g.buffer)]
+	return [2]float64{
+		curr[0] + frac*(next[0]-curr[0]),
+		curr[1] + frac*(next[1]-curr[1]),
+	}
+}
+
+func (g *granular) Err() error {
+	return nil
+}
+
+func (g *granular) IsSilenced() bool {
+	return g.silenced.Load()
+}
+
+func (g *granular) Silence() {
+	g.silenced.Store(true)
+}
+
+func (g *granular) Unsilence() {
+	g.silenced.Store(false)
+}
+
+func (g *granular) ToggleSilence() {
+	if g.IsSilenced() {
+		g.Unsilence()
+	} else {
+		g.Silence()
+	}
+}
+
+func (g *granular) Sample() *Sample {
+	return g.sample.Load()
+}
+
+// SetSample sets the source sample. It is used while there is no input.
+func (g *granular) SetSample(sample *Sample) {
+	g.sample.Store(sample)
+}
+
+func (g *granular) Input() beep.Streamer {
+	return loadStreamer(&g.input)
+}
+
+// SetInput records the input into the rolling buffer, and uses the buffer as the source. nil switches back to the sample.
+func (g *granular) SetInput(input beep.Streamer) {
+	storeStreamer(&g.input, input)
+}
+
+func (g *granular) Grains() GrainParams {
+	return *g.params.Load()
+}
+
+func (g *granular) SetGrains(params GrainParams) error {
+	if err := params.validate(); err != nil {
+		return err
+	}
+	g.params.Store(&params)
+	return nil
+}
@@ -0,0 +1,140 @@
+package streamers
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/gopxl/beep/v2"
+)
+
+// DefaultNoiseSeed is the seed of the noise waveforms, until DynamicStreamer.SetNoiseSeed is called
+const DefaultNoiseSeed = 1
+
+type NoiseColor int
+
+const (
+	// WhiteNoise has equal power per frequency
+	WhiteNoise NoiseColor = iota
+	// PinkNoise has equal power per octave (-3dB per octave)
+	PinkNoise
+	// BrownNoise (aka red noise) is a random walk (-6dB per octave)
+	BrownNoise
+)
+
+func (c NoiseColor) String() string {
+	switch c {
+	case WhiteNoise:
+		return "white"
+	case PinkNoise:
+		return "pink"
+	case BrownNoise:
+		return "brown"
+	default:
+		return strconv.Itoa(int(c))
+	}
+}
+
+// NewNoiseGenerator returns a generator of noise sources, to be used with DynamicStreamer.SetGenerator.
+// Noise ignores the frequency. Every source is seeded by seed, and reseeded by the DynamicStreamer playing it
+// with its own noise seed & the tone index, so a streamer always generates the same noise (e.g. for offline renders).
+func NewNoiseGenerator(color NoiseColor, seed uint64) StreamerGeneratorFunc {
+	return func(_ beep.SampleRate, _ float64) (beep.Streamer, error) {
+		return newNoise(color, seed)
+	}
+}
+
+// seedable is implemented by tone sources with a random generator
+type seedable interface {
+	// setSeed restarts the random sequence, where stream selects one of the sequences of the seed
+	setSeed(seed, stream uint64)
+}
+
+type noise struct {
+	color NoiseColor
+	pcg   *rand.PCG
+	rng   *rand.Rand
+	// filter state of pink & brown noise
+	state [7]float64
+
+	// the seed set by setSeed, applied on the next Stream call
+	seed   atomic.Uint64
+	stream atomic.Uint64
+	reseed atomic.Bool
+}
+
+func newNoise(color NoiseColor, seed uint64) (*noise, error) {
+	if color < WhiteNoise || color > BrownNoise {
+		return nil, fmt.Errorf("noise color unknown")
+	}
+	pcg := rand.NewPCG(seed, 0)
+	return &noise{
+		color: color,
+		pcg:   pcg,
+		rng:   rand.New(pcg),
+	}, nil
+}
+
+func (n *noise) setSeed(seed, stream uint64) {
+	n.seed.Store(seed)
+	n.stream.Store(stream)
+	n.reseed.Store(true)
+}
+
+func (n *noise) Stream(samples [][2]float64) (int, bool) {
+	if n.reseed.CompareAndSwap(true, false) {
+		n.pcg.Seed(n.seed.Load(), n.stream.Load())
+		n.state = [7]float64{}
+	}
+
+	for i := range samples {
+		var v float64
+		switch n.color {
+		case PinkNoise:
+			v = n.pink()
+		case BrownNoise:
+			v = n.brown()
+		default:
+			v = n.white()
+		}
+		samples[i][0] = v
+		samples[i][1] = v
+	}
+	return len(samples), true
+}
+
+func (*noise) Err() error {
+	return nil
+}
+
+// SetFrequency implements Tunable. Noise has no pitch, so the frequency is ignored.
+func (*noise) SetFrequency(float64) error {
+	return nil
+}
+
+func (n *noise) white() float64 {
+	return n.rng.Float64()*2 - 1
+}
+
+// pink filters white noise using Paul Kellet's refined method (see https://www.firstpr.com.au/dsp/pink-noise/)
+func (n *noise) pink() float64 {
+	w := n.white()
+	b := &n.state
+	b[0] = 0.99886*b[0] + w*0.0555179
+	b[1] = 0.99332*b[1] + w*0.0750759
+	b[2] = 0.96900*b[2] + w*0.1538520
+	b[3] = 0.86650*b[3] + w*0.3104856
+	b[4] = 0.55000*b[4] + w*0.5329522
+	b[5] = -0.7616*b[5] - w*0.0168980
+	v := b[0] + b[1] + b[2] + b[3] + b[4] + b[5] + b[6] + w*0.5362
+	b[6] = w * 0.115926
+	return v * 0.11
+}
+
+// brown integrates white noise, with a leak that keeps it from drifting away from 0
+func (n *noise) brown() float64 {
+	b := &n.state
+	b[0] = (b[0] + 0.02*n.white()) / 1.02
+	return b[0] * 3.5
+}
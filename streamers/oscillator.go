@@ -0,0 +1,151 @@
+package streamers
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+
+	"github.com/HuBeZa/synth/streamers/composers"
+)
+
+// Tunable is implemented by tone streamers that can change their frequency while streaming,
+// without restarting (and without resetting their phase).
+type Tunable interface {
+	SetFrequency(freq float64) error
+}
+
+// retriggerable is implemented by tone sources with a time dependent state, that should restart on every attack
+type retriggerable interface {
+	retrigger()
+}
+
+// phaseSetter is implemented by tone sources that can start at an arbitrary point of their cycle.
+// It should be called before the source starts streaming.
+type phaseSetter interface {
+	setPhase(phase float64)
+}
+
+// waveShape returns the amplitude (-1 to 1) of a wave at the given phase (0 to 1).
+// increment is the phase advance per sample (frequency / sample rate), used by band-limited shapes.
+type waveShape func(phase, increment float64) float64
+
+// oscillator is a periodic wave generator with a persistent phase.
+// Frequency & amplitude can be updated atomically while streaming, and are smoothed to avoid clicks.
+type oscillator struct {
+	sampleRate beep.SampleRate
+	shape      waveShape
+	phase      float64
+	frequency  glidingParam
+	amplitude  smoothedParam
+}
+
+func newOscillator(sampleRate beep.SampleRate, freq float64, shape waveShape) (*oscillator, error) {
+	if err := validateFrequency(sampleRate, freq); err != nil {
+		return nil, err
+	}
+
+	o := &oscillator{
+		sampleRate: sampleRate,
+		shape:      shape,
+	}
+	o.frequency.init(freq, sampleRate, DefaultSmoothingTime)
+	o.amplitude.init(1, sampleRate, DefaultSmoothingTime)
+	return o, nil
+}
+
+func newOscillatorGenerator(shape waveShape) StreamerGeneratorFunc {
+	return func(sampleRate beep.SampleRate, freq float64) (beep.Streamer, error) {
+		return newOscillator(sampleRate, freq, shape)
+	}
+}
+
+func (o *oscillator) Stream(samples [][2]float64) (n int, ok bool) {
+	sampleRate := float64(o.sampleRate)
+	for i := range samples {
+		increment := o.frequency.next() / sampleRate
+		v := o.amplitude.next() * o.shape(o.phase, increment)
+		samples[i][0] = v
+		samples[i][1] = v
+		_, o.phase = math.Modf(o.phase + increment)
+	}
+	return len(samples), true
+}
+
+func (*oscillator) Err() error {
+	return nil
+}
+
+func (o *oscillator) Frequency() float64 {
+	return o.frequency.Target()
+}
+
+func (o *oscillator) SetFrequency(freq float64) error {
+	if err := validateFrequency(o.sampleRate, freq); err != nil {
+		return err
+	}
+	o.frequency.SetTarget(freq)
+	return nil
+}
+
+func (o *oscillator) glide(freq float64, length int, transitionType composers.TransitionType) error {
+	if err := validateFrequency(o.sampleRate, freq); err != nil {
+		return err
+	}
+	o.frequency.glideTo(freq, length, transitionType)
+	return nil
+}
+
+func (o *oscillator) setPhase(phase float64) {
+	_, o.phase = math.Modf(phase)
+}
+
+func (o *oscillator) Amplitude() float64 {
+	return o.amplitude.Target()
+}
+
+func (o *oscillator) SetAmplitude(amplitude float64) {
+	o.amplitude.SetTarget(amplitude)
+}
+
+func (o *oscillator) setSmoothing(sampleRate beep.SampleRate, smoothingTime time.Duration) {
+	o.frequency.setSmoothing(sampleRate, smoothingTime)
+	o.amplitude.setSmoothing(sampleRate, smoothingTime)
+}
+
+func validateFrequency(sampleRate beep.SampleRate, freq float64) error {
+	if freq < 0 {
+		return fmt.Errorf("frequency should not be negative")
+	}
+	if freq/float64(sampleRate) >= 1.0/2.0 {
+		return fmt.Errorf("samplerate must be at least 2 times grater then frequency")
+	}
+	return nil
+}
+
+func sineShape(phase, _ float64) float64 {
+	return math.Sin(phase * 2.0 * math.Pi)
+}
+
+func triangleShape(phase, _ float64) float64 {
+	if phase < 0.5 {
+		return 2.0*(1-phase) - 1
+	}
+	return 2.0*phase - 1.0
+}
+
+func squareShape(phase, _ float64) float64 {
+	if phase < 0.5 {
+		return 1.0
+	}
+	return -1.0
+}
+
+func sawtoothShape(phase, _ float64) float64 {
+	return 2.0*phase - 1.0
+}
+
+func reversedSawtoothShape(phase, _ float64) float64 {
+	return 2.0*(1-phase) - 1
+}
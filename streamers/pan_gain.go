@@ -0,0 +1,39 @@
+package streamers
+
+import (
+	"github.com/gopxl/beep/v2"
+)
+
+// panGain balances and amplifies the wrapped streamer, like effects.Pan & effects.Gain.
+// Pan & gain are smoothed parameters, so they can be changed while streaming. A nil pan only amplifies.
+type panGain struct {
+	streamer beep.Streamer
+	pan      *smoothedParam
+	gain     *smoothedParam
+}
+
+func (p *panGain) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = p.streamer.Stream(samples)
+
+	for i := range samples[:n] {
+		pan, gain := 0.0, p.gain.next()
+		if p.pan != nil {
+			pan = p.pan.next()
+		}
+		l, r := samples[i][0], samples[i][1]
+		switch {
+		case pan < 0:
+			l += -pan * r
+			r -= -pan * r
+		case pan > 0:
+			r += pan * l
+			l -= pan * l
+		}
+		samples[i][0], samples[i][1] = l*gain, r*gain
+	}
+	return n, ok
+}
+
+func (p *panGain) Err() error {
+	return p.streamer.Err()
+}
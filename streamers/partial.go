@@ -0,0 +1,29 @@
+package streamers
+
+import (
+	"fmt"
+	"math"
+)
+
+// Partial is an additional tone of additive synthesis, relative to the played note
+type Partial struct {
+	// frequency ratio relative to the played note, e.g. 3 for the 3rd harmonic. Inharmonic ratios are allowed too
+	Ratio float64
+	// amplitude relative to the played note, 0 to 1
+	Amplitude float64
+}
+
+func (p Partial) validate() error {
+	if p.Ratio <= 0 {
+		return fmt.Errorf("partial ratio should be positive")
+	}
+	if p.Amplitude < 0 || p.Amplitude > 1 {
+		return fmt.Errorf("partial amplitude should be between 0 to 1")
+	}
+	return nil
+}
+
+// cents returns the distance of the partial from the played note, in cents
+func (p Partial) cents() float64 {
+	return 1200 * math.Log2(p.Ratio)
+}
@@ -0,0 +1,129 @@
+package streamers
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+)
+
+const (
+	MinPulseWidth = 0.01
+	MaxPulseWidth = 0.99
+)
+
+// PulseWidth sets the duty cycle of the pulse waveform, and its modulation by an LFO (PWM)
+type PulseWidth struct {
+	// part of the cycle with high output, MinPulseWidth to MaxPulseWidth. 0.5 is a square wave
+	Width float64
+	// LFO frequency in Hz. 0 turns PWM off
+	LFORate float64
+	// LFO amplitude, added to & subtracted from width. The modulated width is clamped to MinPulseWidth - MaxPulseWidth
+	LFODepth float64
+}
+
+func DefaultPulseWidth() PulseWidth {
+	return PulseWidth{Width: 0.5}
+}
+
+func (p PulseWidth) validate() error {
+	if p.Width < MinPulseWidth || p.Width > MaxPulseWidth {
+		return fmt.Errorf("pulse width should be between %v to %v", MinPulseWidth, MaxPulseWidth)
+	}
+	if p.LFORate < 0 {
+		return fmt.Errorf("PWM rate should not be negative")
+	}
+	if p.LFODepth < 0 || p.LFODepth > MaxPulseWidth-MinPulseWidth {
+		return fmt.Errorf("PWM depth should be between 0 to %v", MaxPulseWidth-MinPulseWidth)
+	}
+	return nil
+}
+
+// pulseWidthSetter is implemented by tone sources with a pulse width, that can be changed while streaming
+type pulseWidthSetter interface {
+	setPulseWidth(pulseWidth PulseWidth)
+}
+
+// NewPulseGenerator returns a generator of pulse oscillators, to be used with DynamicStreamer.SetGenerator
+func NewPulseGenerator(pulseWidth PulseWidth, bandLimited bool) StreamerGeneratorFunc {
+	return func(sampleRate beep.SampleRate, freq float64) (beep.Streamer, error) {
+		return newPulseOscillator(sampleRate, freq, pulseWidth, bandLimited)
+	}
+}
+
+// pulseOscillator is an oscillator whose duty cycle can be set, and modulated by a sine LFO.
+type pulseOscillator struct {
+	*oscillator
+	bandLimited bool
+	width       smoothedParam
+	lfoRate     atomic.Uint64
+	lfoDepth    atomic.Uint64
+	lfoPhase    float64
+}
+
+func newPulseOscillator(sampleRate beep.SampleRate, freq float64, pulseWidth PulseWidth, bandLimited bool) (*pulseOscillator, error) {
+	if err := pulseWidth.validate(); err != nil {
+		return nil, err
+	}
+
+	o, err := newOscillator(sampleRate, freq, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &pulseOscillator{
+		oscillator:  o,
+		bandLimited: bandLimited,
+	}
+	p.width.init(pulseWidth.Width, sampleRate, DefaultSmoothingTime)
+	p.setPulseWidth(pulseWidth)
+	return p, nil
+}
+
+func (p *pulseOscillator) Stream(samples [][2]float64) (n int, ok bool) {
+	sampleRate := float64(p.sampleRate)
+	lfoIncrement := loadFloat(&p.lfoRate) / sampleRate
+	lfoDepth := loadFloat(&p.lfoDepth)
+
+	for i := range samples {
+		width := p.width.next()
+		if lfoDepth > 0 {
+			width += lfoDepth * math.Sin(2*math.Pi*p.lfoPhase)
+			width = min(max(width, MinPulseWidth), MaxPulseWidth)
+			_, p.lfoPhase = math.Modf(p.lfoPhase + lfoIncrement)
+		}
+
+		increment := p.frequency.next() / sampleRate
+		v := p.amplitude.next() * p.pulse(width, increment)
+		samples[i][0] = v
+		samples[i][1] = v
+		_, p.phase = math.Modf(p.phase + increment)
+	}
+	return len(samples), true
+}
+
+func (p *pulseOscillator) pulse(width, increment float64) float64 {
+	v := -1.0
+	if p.phase < width {
+		v = 1.0
+	}
+	if p.bandLimited {
+		// rising edge at phase 0, falling edge at width
+		_, fallPhase := math.Modf(p.phase + 1 - width)
+		v += polyBLEP(p.phase, increment) - polyBLEP(fallPhase, increment)
+	}
+	return v
+}
+
+func (p *pulseOscillator) setPulseWidth(pulseWidth PulseWidth) {
+	p.width.SetTarget(pulseWidth.Width)
+	storeFloat(&p.lfoRate, pulseWidth.LFORate)
+	storeFloat(&p.lfoDepth, pulseWidth.LFODepth)
+}
+
+func (p *pulseOscillator) setSmoothing(sampleRate beep.SampleRate, smoothingTime time.Duration) {
+	p.oscillator.setSmoothing(sampleRate, smoothingTime)
+	p.width.setSmoothing(sampleRate, smoothingTime)
+}
@@ -0,0 +1,163 @@
+package streamers
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+)
+
+type RingModulator interface {
+	beep.Streamer
+	IsSilenced() bool
+	Silence()
+	Unsilence()
+	ToggleSilence()
+	Carrier() beep.Streamer
+	SetCarrier(carrier beep.Streamer)
+	Modulator() beep.Streamer
+	SetModulator(modulator beep.Streamer)
+	Mix() float64
+	SetMix(mix float64) error
+	SetSmoothing(smoothingTime time.Duration) error
+}
+
+// ringModulator multiplies the carrier by the modulator.
+// Both inputs are consumed by the ring modulator, so they should not be played by any other streamer.
+type ringModulator struct {
+	sampleRate beep.SampleRate
+	carrier    atomic.Pointer[beep.Streamer]
+	modulator  atomic.Pointer[beep.Streamer]
+	mix        smoothedParam
+	silenced   atomic.Bool
+	buffer     [][2]float64
+}
+
+func NewRingModulator(sampleRate beep.SampleRate, mix float64) (RingModulator, error) {
+	if err := validateMix(mix); err != nil {
+		return nil, err
+	}
+
+	r := &ringModulator{sampleRate: sampleRate}
+	r.mix.init(mix, sampleRate, DefaultSmoothingTime)
+	return r, nil
+}
+
+func (r *ringModulator) Stream(samples [][2]float64) (n int, ok bool) {
+	carrier, modulator := r.Carrier(), r.Modulator()
+	if r.IsSilenced() || carrier == nil {
+		return silenceStreamer.Stream(samples)
+	}
+
+	n, _ = carrier.Stream(samples)
+	clear(samples[n:])
+	if modulator == nil {
+		return len(samples), true
+	}
+
+	modulation := r.getBuffer(len(samples))
+	if modulator == carrier {
+		copy(modulation, samples)
+	} else {
+		n, _ = modulator.Stream(modulation)
+		clear(modulation[n:])
+	}
+
+	for i := range samples {
+		mix := r.mix.next()
+		samples[i][0] *= 1 - mix + modulation[i][0]*mix
+		samples[i][1] *= 1 - mix + modulation[i][1]*mix
+	}
+
+	return len(samples), true
+}
+
+func (r *ringModulator) Err() error {
+	return nil
+}
+
+func (r *ringModulator) IsSilenced() bool {
+	return r.silenced.Load()
+}
+
+func (r *ringModulator) Silence() {
+	r.silenced.Store(true)
+}
+
+func (r *ringModulator) Unsilence() {
+	r.silenced.Store(false)
+}
+
+func (r *ringModulator) ToggleSilence() {
+	if r.IsSilenced() {
+		r.Unsilence()
+	} else {
+		r.Silence()
+	}
+}
+
+func (r *ringModulator) Carrier() beep.Streamer {
+	return loadStreamer(&r.carrier)
+}
+
+func (r *ringModulator) SetCarrier(carrier beep.Streamer) {
+	storeStreamer(&r.carrier, carrier)
+}
+
+func (r *ringModulator) Modulator() beep.Streamer {
+	return loadStreamer(&r.modulator)
+}
+
+func (r *ringModulator) SetModulator(modulator beep.Streamer) {
+	storeStreamer(&r.modulator, modulator)
+}
+
+func (r *ringModulator) Mix() float64 {
+	return r.mix.Target()
+}
+
+func (r *ringModulator) SetMix(mix float64) error {
+	if err := validateMix(mix); err != nil {
+		return err
+	}
+	r.mix.SetTarget(mix)
+	return nil
+}
+
+func (r *ringModulator) SetSmoothing(smoothingTime time.Duration) error {
+	if err := validateSmoothing(smoothingTime); err != nil {
+		return err
+	}
+	r.mix.setSmoothing(r.sampleRate, smoothingTime)
+	return nil
+}
+
+func validateMix(mix float64) error {
+	if mix < 0 || mix > 1 {
+		return fmt.Errorf("mix should be between 0 (carrier only) to 1 (modulated only)")
+	}
+	return nil
+}
+
+func (r *ringModulator) getBuffer(length int) [][2]float64 {
+	if cap(r.buffer) < length {
+		r.buffer = make([][2]float64, length)
+	}
+	return r.buffer[:length]
+}
+
+func loadStreamer(p *atomic.Pointer[beep.Streamer]) beep.Streamer {
+	if streamer := p.Load(); streamer != nil {
+		return *streamer
+	}
+	return nil
+}
+
+func storeStreamer(p *atomic.Pointer[beep.Streamer], streamer beep.Streamer) {
+	if streamer == nil {
+		p.Store(nil)
+	} else {
+		p.Store(&streamer)
+	}
+}
@@ -0,0 +1,308 @@
+package streamers
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/gopxl/beep/v2/flac"
+	"github.com/gopxl/beep/v2/mp3"
+	"github.com/gopxl/beep/v2/wav"
+
+	"github.com/HuBeZa/synth/streamers/frequencies"
+)
+
+const builtinSampleName = "built-in"
+
+var builtinSample = newBuiltinSample()
+
+// Sample is a decoded audio recording, kept in memory
+type Sample struct {
+	name       string
+	sampleRate beep.SampleRate
+	data       [][2]float64
+}
+
+func NewSample(name string, sampleRate beep.SampleRate, data [][2]float64) (*Sample, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("sample rate should be positive")
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("sample is empty")
+	}
+	return &Sample{name, sampleRate, data}, nil
+}
+
+// BuiltinSample returns a short plucked tone at C4, so a sampler can be played before a file is loaded
+func BuiltinSample() *Sample {
+	return builtinSample
+}
+
+func newBuiltinSample() *Sample {
+	const sampleRate = beep.SampleRate(44100)
+	freq := frequencies.C4().Frequency()
+
+	data := make([][2]float64, sampleRate.N(time.Second))
+	for i := range data {
+		t := float64(i) / float64(sampleRate)
+		phase := math.Mod(t*freq, 1)
+		// bright attack, decaying into a sine
+		brightness := math.Exp(-t * 12)
+		v := math.Exp(-t*3) * ((1-brightness)*sineShape(phase, 0) + brightness*sawtoothShape(phase, 0))
+		data[i] = [2]float64{v, v}
+	}
+
+	sample, _ := NewSample(builtinSampleName, sampleRate, data)
+	return sample
+}
+
+// LoadSample reads a WAV, FLAC or MP3 file, by its extension
+func LoadSample(path string) (*Sample, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var streamer beep.StreamSeekCloser
+	var format beep.Format
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".wav":
+		streamer, format, err = wav.Decode(file)
+	case ".flac":
+		streamer, format, err = flac.Decode(file)
+	case ".mp3":
+		streamer, format, err = mp3.Decode(file)
+	default:
+		err = fmt.Errorf("unsupported sample format %q (expected .wav, .flac or .mp3)", ext)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	defer streamer.Close()
+
+	data := make([][2]float64, 0, max(streamer.Len(), 0))
+	buf := make([][2]float64, 512)
+	for {
+		n, ok := streamer.Stream(buf)
+		data = append(data, buf[:n]...)
+		if !ok {
+			break
+		}
+	}
+	if err := streamer.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewSample(filepath.Base(path), format.SampleRate, data)
+}
+
+func (s *Sample) Name() string {
+	return s.name
+}
+
+func (s *Sample) SampleRate() beep.SampleRate {
+	return s.sampleRate
+}
+
+// Len returns the number of frames of the sample
+func (s *Sample) Len() int {
+	return len(s.data)
+}
+
+func (s *Sample) Duration() time.Duration {
+	return s.sampleRate.D(len(s.data))
+}
+
+// Peaks splits the sample into n equal parts, and returns the peak amplitude of each part
+func (s *Sample) Peaks(n int) []float64 {
+	peaks := make([]float64, n)
+	for i := range peaks {
+		for _, frame := range s.data[i*len(s.data)/n : (i+1)*len(s.data)/n] {
+			peaks[i] = max(peaks[i], math.Abs(frame[0]), math.Abs(frame[1]))
+		}
+	}
+	return peaks
+}
+
+// frame interpolates linearly between the frames around pos
+func (s *Sample) frame(pos float64) [2]float64 {
+	i := int(pos)
+	if i >= len(s.data)-1 {
+		return s.data[len(s.data)-1]
+	}
+	frac := pos - float64(i)
+	curr, next := s.data[i], s.data[i+1]
+	return [2]float64{
+		curr[0] + frac*(next[0]-curr[0]),
+		curr[1] + frac*(next[1]-curr[1]),
+	}
+}
+
+type LoopMode int
+
+const (
+	// OneShot plays the whole sample once, ignoring the key release
+	OneShot LoopMode = iota
+	// SustainLoop repeats the loop while the key is held, and plays the rest of the sample on release
+	SustainLoop
+	// Loop repeats the loop until the release of the envelope ends
+	Loop
+)
+
+func LoopModes() []LoopMode {
+	return []LoopMode{OneShot, SustainLoop, Loop}
+}
+
+func (m LoopMode) Equals(other LoopMode) bool {
+	return m == other
+}
+
+func (m LoopMode) String() string {
+	switch m {
+	case OneShot:
+		return "one-shot"
+	case SustainLoop:
+		return "sustain loop"
+	case Loop:
+		return "loop"
+	default:
+		return strconv.Itoa(int(m))
+	}
+}
+
+// SampleLoop sets how a sample is played. Start & End are frame indexes of the sample.
+type SampleLoop struct {
+	Mode  LoopMode
+	Start int
+	End   int
+}
+
+// FullSampleLoop returns a loop over the whole sample
+func FullSampleLoop(sample *Sample, mode LoopMode) SampleLoop {
+	return SampleLoop{Mode: mode, Start: 0, End: sample.Len()}
+}
+
+func (l SampleLoop) validate(sample *Sample) error {
+	if l.Mode < OneShot || l.Mode > Loop {
+		return fmt.Errorf("loop mode unknown")
+	}
+	if l.Mode == OneShot {
+		return nil
+	}
+	if l.Start < 0 || l.End > sample.Len() || l.Start >= l.End {
+		return fmt.Errorf("loop points should be within the sample, and loop start should be before loop end")
+	}
+	return nil
+}
+
+// releasable is implemented by tone sources that change their playback when the key is released
+type releasable interface {
+	release()
+}
+
+// oneShotter is implemented by tone sources that may play their current note to its end, ignoring the key release
+type oneShotter interface {
+	isOneShot() bool
+}
+
+// NewSampleGenerator returns a generator of sample players, to be used with DynamicStreamer.SetGenerator.
+// The sample is pitch shifted by resampling, so it plays in its original speed at rootFreq.
+func NewSampleGenerator(sample *Sample, rootFreq float64, loop SampleLoop) StreamerGeneratorFunc {
+	return func(sampleRate beep.SampleRate, freq float64) (beep.Streamer, error) {
+		return newSamplePlayer(sampleRate, freq, sample, rootFreq, loop)
+	}
+}
+
+// samplePlayer reuses the frequency & amplitude params of the oscillator. Its phase is unused.
+type samplePlayer struct {
+	*oscillator
+	sample   *Sample
+	rootFreq float64
+	loop     SampleLoop
+	// position in sample frames
+	pos       float64
+	playing   bool
+	restart   atomic.Bool
+	releasing atomic.Bool
+}
+
+func newSamplePlayer(sampleRate beep.SampleRate, freq float64, sample *Sample, rootFreq float64, loop SampleLoop) (*samplePlayer, error) {
+	if sample == nil {
+		return nil, fmt.Errorf("sample is missing")
+	}
+	if rootFreq <= 0 {
+		return nil, fmt.Errorf("sample root frequency should be positive")
+	}
+	if err := loop.validate(sample); err != nil {
+		return nil, err
+	}
+
+	o, err := newOscillator(sampleRate, freq, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &samplePlayer{
+		oscillator: o,
+		sample:     sample,
+		rootFreq:   rootFreq,
+		loop:       loop,
+	}, nil
+}
+
+func (p *samplePlayer) Stream(samples [][2]float64) (n int, ok bool) {
+	// nothing is played until the first attack
+	if p.restart.CompareAndSwap(true, false) {
+		p.pos = 0
+		p.playing = true
+		// a new note should not slide from the pitch of the previous one
+		p.frequency.snap()
+	}
+
+	// playback speed at the root frequency, to compensate for a different sample rate
+	rate := float64(p.sample.sampleRate) / float64(p.sampleRate) / p.rootFreq
+	end := float64(p.sample.Len())
+	loopStart, loopEnd := float64(p.loop.Start), float64(p.loop.End)
+	looping := p.loop.Mode == Loop || (p.loop.Mode == SustainLoop && !p.releasing.Load())
+
+	for i := range samples {
+		increment := p.frequency.next() * rate
+		amplitude := p.amplitude.next()
+
+		if looping && p.pos >= loopEnd {
+			p.pos = loopStart + math.Mod(p.pos-loopStart, loopEnd-loopStart)
+		}
+		if !p.playing || p.pos >= end || increment == 0 {
+			samples[i] = [2]float64{}
+			continue
+		}
+
+		frame := p.sample.frame(p.pos)
+		samples[i][0] = amplitude * frame[0]
+		samples[i][1] = amplitude * frame[1]
+		p.pos += increment
+	}
+
+	// the player is never drained, so it can be retriggered
+	return len(samples), true
+}
+
+func (p *samplePlayer) retrigger() {
+	p.releasing.Store(false)
+	p.restart.Store(true)
+}
+
+func (p *samplePlayer) release() {
+	p.releasing.Store(true)
+}
+
+func (p *samplePlayer) isOneShot() bool {
+	return p.loop.Mode == OneShot
+}
@@ -0,0 +1,33 @@
+package streamers
+
+import (
+	"testing"
+
+	"github.com/HuBeZa/synth/streamers/frequencies"
+)
+
+func TestSampleOneShot(t *testing.T) {
+	sample := BuiltinSample()
+	tests := []struct {
+		mode         LoopMode
+		wantReleased bool
+	}{
+		{mode: OneShot, wantReleased: false},
+		{mode: SustainLoop, wantReleased: true},
+		{mode: Loop, wantReleased: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			freq := frequencies.FromMidi(60)
+			streamer, err := newDynamicStreamer(44100, freq, 0, 1, NewSampleGenerator(sample, freq.Frequency(), FullSampleLoop(sample, tt.mode)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			streamer.TriggerAttack()
+			streamer.TriggerRelease()
+			if streamer.isReleased != tt.wantReleased {
+				t.Errorf("released = %v, want %v", streamer.isReleased, tt.wantReleased)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package scales
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+var (
+	scaleMajor         = scale{"major", []int{0, 2, 4, 5, 7, 9, 11}}
+	scaleMinor         = scale{"minor", []int{0, 2, 3, 5, 7, 8, 10}}
+	scaleDorian        = scale{"dorian", []int{0, 2, 3, 5, 7, 9, 10}}
+	scalePentatonic    = scale{"pentatonic", []int{0, 2, 4, 7, 9}}
+	scaleBlues         = scale{"blues", []int{0, 3, 5, 6, 7, 10}}
+	scaleHarmonicMinor = scale{"harmonic minor", []int{0, 2, 3, 5, 7, 8, 11}}
+
+	noteNames = []string{"C", "C♯", "D", "E♭", "E", "F", "F♯", "G", "A♭", "A", "B♭", "B"}
+)
+
+// NoteNames returns the names of the 12 semitones, starting at C
+func NoteNames() []string {
+	return slices.Clone(noteNames)
+}
+
+// Scale is a set of semitones above the root, within an octave
+type Scale interface {
+	Name() string
+	Semitones() []int
+	// Contains returns true if the semitone above the root, in any octave, is in the scale
+	Contains(semitone int) bool
+	Equals(other Scale) bool
+}
+
+type scale struct {
+	name      string
+	semitones []int
+}
+
+// NewCustomScale returns a scale of the given semitones above the root. The root (0) is always added.
+func NewCustomScale(semitones []int) (Scale, error) {
+	res := []int{0}
+	for _, semitone := range semitones {
+		if semitone < 0 || semitone >= 12 {
+			return nil, fmt.Errorf("semitones should be between 0 to 11")
+		}
+		if !slices.Contains(res, semitone) {
+			res = append(res, semitone)
+		}
+	}
+	slices.Sort(res)
+	return scale{"custom", res}, nil
+}
+
+func (s scale) Name() string {
+	return s.name
+}
+
+func (s scale) Semitones() []int {
+	semitonesCopy := make([]int, len(s.semitones))
+	copy(semitonesCopy, s.semitones)
+	return semitonesCopy
+}
+
+func (s scale) Contains(semitone int) bool {
+	return slices.Contains(s.semitones, mod(semitone, 12))
+}
+
+func (s scale) String() string {
+	return s.name
+}
+
+// Equals compares the names, so a custom scale equals any other custom scale
+func (s scale) Equals(other Scale) bool {
+	return other != nil && s.name == other.Name()
+}
+
+func Scales() []Scale {
+	return []Scale{scaleMajor, scaleMinor, scaleDorian, scalePentatonic, scaleBlues, scaleHarmonicMinor}
+}
+
+// Key is a scale played from a root
+type Key struct {
+	// Root is the number of semitones above C
+	Root  int
+	Scale Scale
+}
+
+// DefaultKey returns C major
+func DefaultKey() Key {
+	return Key{Root: 0, Scale: scaleMajor}
+}
+
+// ContainsStep returns true if the step of a tuning with the given divisions per octave is in the key.
+// Step 0 is C, and each step is mapped to its nearest semitone, if it is the nearest step to that semitone.
+func (k Key) ContainsStep(step, divisions int) bool {
+	degree := mod(step, divisions)
+	semitone := int(math.Round(float64(degree*12) / float64(divisions)))
+	if nearestStep(semitone, divisions) != degree {
+		return false
+	}
+	return k.Scale.Contains(semitone - k.Root)
+}
+
+// RootStep returns the first step of the root, in a tuning with the given divisions per octave
+func (k Key) RootStep(divisions int) int {
+	return nearestStep(k.Root, divisions)
+}
+
+func nearestStep(semitone, divisions int) int {
+	return int(math.Round(float64(semitone*divisions) / 12))
+}
+
+func mod(a, b int) int {
+	return ((a % b) + b) % b
+}
@@ -0,0 +1,522 @@
+package streamers
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+
+	"github.com/HuBeZa/synth/streamers/composers"
+	"github.com/HuBeZa/synth/streamers/frequencies"
+)
+
+const (
+	// DefaultSfzVelocity is the velocity of notes played from a computer keyboard, which has no velocity
+	DefaultSfzVelocity = 100
+	MaxSfzVelocity     = 127
+
+	minSfzNote = 0
+	maxSfzNote = 127
+)
+
+var (
+	sfzHeaderRegexp = regexp.MustCompile(`<(\w+)>`)
+	sfzOpcodeRegexp = regexp.MustCompile(`(?:^|\s)(\w+)=`)
+)
+
+// SfzRegion is a sample mapped to a range of keys & velocities
+type SfzRegion struct {
+	Sample *Sample
+	// MIDI notes & velocities ranges, inclusive
+	LoKey, HiKey int
+	LoVel, HiVel int
+	// the MIDI note in which the sample plays in its original pitch
+	PitchKeycenter int
+	// pitch change per key, in cents. 0 plays all keys in the same pitch (e.g. drums)
+	PitchKeytrack float64
+	// Tune in cents, and Transpose in semitones, are added to the pitch of the sample
+	Tune      float64
+	Transpose int
+	// Volume in dB
+	Volume float64
+	Loop   SampleLoop
+	// OneShot regions are played to the end of the sample, ignoring the key release
+	OneShot bool
+	// round robin - the region is played on attacks number SeqPosition, SeqPosition + SeqLength... (1-based)
+	SeqLength   int
+	SeqPosition int
+}
+
+// matches returns true if the region should play the note. attack is the number of attacks before the current one.
+func (r SfzRegion) matches(note, velocity, attack int) bool {
+	return note >= r.LoKey && note <= r.HiKey &&
+		velocity >= r.LoVel && velocity <= r.HiVel &&
+		attack%r.SeqLength+1 == r.SeqPosition
+}
+
+// rootFrequency returns the frequency in which the sample plays in its original speed, after tuning
+func (r SfzRegion) rootFrequency() float64 {
+	return midiFrequency(r.PitchKeycenter) * math.Pow(2, -(r.Tune+100*float64(r.Transpose))/1200)
+}
+
+// playbackFrequency returns the frequency to play the region's sample at, for the given note frequency.
+// It scales the distance from the pitch center by the key tracking.
+func (r SfzRegion) playbackFrequency(freq float64) float64 {
+	center := midiFrequency(r.PitchKeycenter)
+	return center * math.Pow(freq/center, r.PitchKeytrack/100)
+}
+
+// SfzInstrument is a multi-sampled instrument, loaded from an SFZ file (see https://sfzformat.com)
+type SfzInstrument struct {
+	name    string
+	regions []SfzRegion
+}
+
+func (i *SfzInstrument) Name() string {
+	return i.name
+}
+
+func (i *SfzInstrument) Regions() []SfzRegion {
+	return i.regions
+}
+
+// SeqLength returns the longest round robin of the instrument
+func (i *SfzInstrument) SeqLength() int {
+	seqLength := 1
+	for _, region := range i.regions {
+		seqLength = max(seqLength, region.SeqLength)
+	}
+	return seqLength
+}
+
+// region returns the first region that matches the note & velocity, in the given attack
+func (i *SfzInstrument) region(note, velocity, attack int) (SfzRegion, bool) {
+	for _, region := range i.regions {
+		if region.matches(note, velocity, attack) {
+			return region, true
+		}
+	}
+	return SfzRegion{}, false
+}
+
+// LoadSfzInstrument reads an SFZ file & all of its samples.
+// Supported headers are <control>, <global>, <group> & <region>. Unsupported opcodes are ignored.
+func LoadSfzInstrument(path string) (*SfzInstrument, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	instrument := &SfzInstrument{name: filepath.Base(path)}
+	samples := make(map[string]*Sample)
+	defaultPath := ""
+
+	// opcodes of the current header, and of the headers it inherits from
+	global, group, region := map[string]string{}, map[string]string{}, map[string]string{}
+	header := ""
+
+	addRegion := func() error {
+		if header != "region" {
+			return nil
+		}
+		opcodes := mergeOpcodes(global, group, region)
+		r, err := parseSfzRegion(opcodes, filepath.Dir(path), defaultPath, samples)
+		if err != nil {
+			return fmt.Errorf("%v: region %v: %w", instrument.name, len(instrument.regions)+1, err)
+		}
+		instrument.regions = append(instrument.regions, r)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(file)
+	inComment := false
+	for scanner.Scan() {
+		var line string
+		line, inComment = stripSfzComments(scanner.Text(), inComment)
+
+		// split the line by headers, so a header & its opcodes may share a line
+		for _, part := range splitSfzHeaders(line) {
+			if part.header != "" {
+				if err := addRegion(); err != nil {
+					return nil, err
+				}
+
+				header = part.header
+				switch header {
+				case "global":
+					global, group = map[string]string{}, map[string]string{}
+				case "group":
+					group = map[string]string{}
+				}
+				region = map[string]string{}
+			}
+
+			for key, value := range parseSfzOpcodes(part.opcodes) {
+				switch header {
+				case "control":
+					if key == "default_path" {
+						defaultPath = value
+					}
+				case "global":
+					global[key] = value
+				case "group":
+					group[key] = value
+				default:
+					region[key] = value
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := addRegion(); err != nil {
+		return nil, err
+	}
+
+	if len(instrument.regions) == 0 {
+		return nil, fmt.Errorf("%v has no regions", instrument.name)
+	}
+	return instrument, nil
+}
+
+// stripSfzComments removes line (//) & block (/* */) comments. inComment is true within a block comment.
+func stripSfzComments(line string, inComment bool) (string, bool) {
+	var sb strings.Builder
+	for len(line) > 0 {
+		if inComment {
+			end := strings.Index(line, "*/")
+			if end == -1 {
+				return sb.String(), true
+			}
+			line, inComment = line[end+2:], false
+			continue
+		}
+
+		lineComment, blockComment := strings.Index(line, "//"), strings.Index(line, "/*")
+		if lineComment != -1 && (blockComment == -1 || lineComment < blockComment) {
+			sb.WriteString(line[:lineComment])
+			break
+		}
+		if blockComment == -1 {
+			sb.WriteString(line)
+			break
+		}
+		sb.WriteString(line[:blockComment])
+		line, inComment = line[blockComment+2:], true
+	}
+	return sb.String(), inComment
+}
+
+type sfzLinePart struct {
+	// empty if the opcodes continue the previous header
+	header  string
+	opcodes string
+}
+
+func splitSfzHeaders(line string) []sfzLinePart {
+	indexes := sfzHeaderRegexp.FindAllStringSubmatchIndex(line, -1)
+	if len(indexes) == 0 {
+		return []sfzLinePart{{opcodes: line}}
+	}
+
+	parts := []sfzLinePart{{opcodes: line[:indexes[0][0]]}}
+	for i, index := range indexes {
+		end := len(line)
+		if i+1 < len(indexes) {
+			end = indexes[i+1][0]
+		}
+		parts = append(parts, sfzLinePart{
+			header:  line[index[2]:index[3]],
+			opcodes: line[index[1]:end],
+		})
+	}
+	return parts
+}
+
+// parseSfzOpcodes returns the key=value pairs of the text. The value of an opcode ends where the next opcode starts,
+// so sample paths may contain spaces.
+func parseSfzOpcodes(text string) map[string]string {
+	opcodes := make(map[string]string)
+	indexes := sfzOpcodeRegexp.FindAllStringSubmatchIndex(text, -1)
+	for i, index := range indexes {
+		end := len(text)
+		if i+1 < len(indexes) {
+			end = indexes[i+1][0]
+		}
+		opcodes[text[index[2]:index[3]]] = strings.TrimSpace(text[index[1]:end])
+	}
+	return opcodes
+}
+
+func mergeOpcodes(levels ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, level := range levels {
+		for key, value := range level {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+func parseSfzRegion(opcodes map[string]string, dir, defaultPath string, samples map[string]*Sample) (SfzRegion, error) {
+	r := SfzRegion{
+		LoKey:          0,
+		HiKey:          127,
+		LoVel:          1,
+		HiVel:          MaxSfzVelocity,
+		PitchKeycenter: 60,
+		PitchKeytrack:  100,
+		SeqLength:      1,
+		SeqPosition:    1,
+	}
+
+	samplePath, ok := opcodes["sample"]
+	if !ok {
+		return r, fmt.Errorf("sample is missing")
+	}
+	samplePath = filepath.Join(dir, filepath.FromSlash(strings.ReplaceAll(defaultPath+samplePath, `\`, "/")))
+	if sample, ok := samples[samplePath]; ok {
+		r.Sample = sample
+	} else {
+		sample, err := LoadSample(samplePath)
+		if err != nil {
+			return r, err
+		}
+		samples[samplePath] = sample
+		r.Sample = sample
+	}
+
+	var err error
+	parseNote := func(key string, dst *int) {
+		if value, ok := opcodes[key]; ok && err == nil {
+			*dst, err = parseSfzNote(value)
+		}
+	}
+	parseInt := func(key string, dst *int) {
+		if value, ok := opcodes[key]; ok && err == nil {
+			*dst, err = strconv.Atoi(value)
+		}
+	}
+	parseFloat := func(key string, dst *float64) {
+		if value, ok := opcodes[key]; ok && err == nil {
+			*dst, err = strconv.ParseFloat(value, 64)
+		}
+	}
+
+	// key sets the range & the pitch center, and can be overridden by the specific opcodes
+	if _, ok := opcodes["key"]; ok {
+		parseNote("key", &r.LoKey)
+		r.HiKey, r.PitchKeycenter = r.LoKey, r.LoKey
+	}
+	parseNote("lokey", &r.LoKey)
+	parseNote("hikey", &r.HiKey)
+	parseNote("pitch_keycenter", &r.PitchKeycenter)
+	parseInt("lovel", &r.LoVel)
+	parseInt("hivel", &r.HiVel)
+	parseFloat("pitch_keytrack", &r.PitchKeytrack)
+	parseFloat("tune", &r.Tune)
+	parseInt("transpose", &r.Transpose)
+	parseFloat("volume", &r.Volume)
+	parseInt("seq_length", &r.SeqLength)
+	parseInt("seq_position", &r.SeqPosition)
+
+	loopStart, loopEnd := 0, r.Sample.Len()-1
+	parseInt("loop_start", &loopStart)
+	parseInt("loopstart", &loopStart)
+	parseInt("loop_end", &loopEnd)
+	parseInt("loopend", &loopEnd)
+	if err != nil {
+		return r, err
+	}
+	if r.SeqLength < 1 || r.SeqPosition < 1 || r.SeqPosition > r.SeqLength {
+		return r, fmt.Errorf("seq_position should be between 1 to seq_length")
+	}
+
+	// loop_end is inclusive
+	r.Loop = SampleLoop{Mode: OneShot, Start: loopStart, End: min(loopEnd+1, r.Sample.Len())}
+	switch loopMode := opcodes["loop_mode"]; loopMode {
+	case "", "no_loop":
+	case "one_shot":
+		r.OneShot = true
+	case "loop_continuous":
+		r.Loop.Mode = Loop
+	case "loop_sustain":
+		r.Loop.Mode = SustainLoop
+	default:
+		return r, fmt.Errorf("loop_mode %q unknown", loopMode)
+	}
+	if err := r.Loop.validate(r.Sample); err != nil {
+		return r, err
+	}
+
+	return r, nil
+}
+
+// parseSfzNote parses a MIDI note number, or a note name (e.g. c4, c#4, db4) where c4 is middle C (60)
+func parseSfzNote(value string) (int, error) {
+	if note, err := strconv.Atoi(value); err == nil {
+		return note, nil
+	}
+
+	freq, err := frequencies.Parse(value)
+	if err != nil {
+		return 0, err
+	}
+	note := frequencies.NearestStep(freq.Frequency())
+	if note < minSfzNote || note > maxSfzNote {
+		return 0, fmt.Errorf("note %q is out of the MIDI range (%v-%v)", value, minSfzNote, maxSfzNote)
+	}
+	// names with a cents offset have no midi note
+	if _, cents := frequencies.Nearest(freq.Frequency()); math.Abs(cents) >= 0.5 {
+		return 0, fmt.Errorf("note %q has a cents offset", value)
+	}
+	return note, nil
+}
+
+func midiFrequency(note int) float64 {
+	return frequencies.FromMidi(note).Frequency()
+}
+
+// frequencyMidi returns the MIDI note closest to freq. Beyond the MIDI range it matches no region.
+func frequencyMidi(freq float64) int {
+	return frequencies.NearestStep(freq)
+}
+
+// NewSfzGenerator returns a generator of SFZ instrument players, to be used with DynamicStreamer.SetGenerator.
+// velocity selects the velocity layer of the instrument.
+func NewSfzGenerator(instrument *SfzInstrument, velocity int) StreamerGeneratorFunc {
+	return func(sampleRate beep.SampleRate, freq float64) (beep.Streamer, error) {
+		return newSfzPlayer(sampleRate, freq, instrument, velocity)
+	}
+}
+
+// sfzPlayer selects the region of the played note on every attack, and plays it with a sample player
+type sfzPlayer struct {
+	sampleRate beep.SampleRate
+	instrument *SfzInstrument
+	velocity   int
+	frequency  atomic.Uint64
+	smoothing  atomic.Int64
+	// number of attacks, for round robins
+	attacks int
+
+	// the player of the current region, and its region. nil if no region matches the note.
+	player atomic.Pointer[sfzRegionPlayer]
+}
+
+type sfzRegionPlayer struct {
+	*samplePlayer
+	region SfzRegion
+}
+
+func newSfzPlayer(sampleRate beep.SampleRate, freq float64, instrument *SfzInstrument, velocity int) (*sfzPlayer, error) {
+	if instrument == nil {
+		return nil, fmt.Errorf("SFZ instrument is missing")
+	}
+	if velocity < 1 || velocity > MaxSfzVelocity {
+		return nil, fmt.Errorf("velocity should be between 1 to %v", MaxSfzVelocity)
+	}
+	if err := validateFrequency(sampleRate, freq); err != nil {
+		return nil, err
+	}
+
+	p := &sfzPlayer{
+		sampleRate: sampleRate,
+		instrument: instrument,
+		velocity:   velocity,
+	}
+	storeFloat(&p.frequency, freq)
+	p.smoothing.Store(int64(DefaultSmoothingTime))
+	return p, nil
+}
+
+func (p *sfzPlayer) Stream(samples [][2]float64) (n int, ok bool) {
+	player := p.player.Load()
+	if player == nil {
+		clear(samples)
+		return len(samples), true
+	}
+	return player.Stream(samples)
+}
+
+func (*sfzPlayer) Err() error {
+	return nil
+}
+
+func (p *sfzPlayer) SetFrequency(freq float64) error {
+	if err := validateFrequency(p.sampleRate, freq); err != nil {
+		return err
+	}
+	storeFloat(&p.frequency, freq)
+	if player := p.player.Load(); player != nil {
+		return player.SetFrequency(player.region.playbackFrequency(freq))
+	}
+	return nil
+}
+
+func (p *sfzPlayer) glide(freq float64, length int, transitionType composers.TransitionType) error {
+	if err := validateFrequency(p.sampleRate, freq); err != nil {
+		return err
+	}
+	storeFloat(&p.frequency, freq)
+	if player := p.player.Load(); player != nil {
+		return player.glide(player.region.playbackFrequency(freq), length, transitionType)
+	}
+	return nil
+}
+
+// retrigger selects the region of the current note, in the next round robin positions
+func (p *sfzPlayer) retrigger() {
+	freq := loadFloat(&p.frequency)
+	if freq <= 0 {
+		return
+	}
+
+	region, ok := p.instrument.region(frequencyMidi(freq), p.velocity, p.attacks)
+	p.attacks++
+	if !ok {
+		p.player.Store(nil)
+		return
+	}
+
+	// the pitch is validated against the sample rate, so notes too high for a region are not played
+	player, err := newSamplePlayer(p.sampleRate, region.playbackFrequency(freq), region.Sample, region.rootFrequency(), region.Loop)
+	if err != nil {
+		p.player.Store(nil)
+		return
+	}
+	smoothing := time.Duration(p.smoothing.Load())
+	player.setSmoothing(p.sampleRate, smoothing)
+	player.amplitude.init(math.Pow(10, region.Volume/20), p.sampleRate, smoothing)
+	player.retrigger()
+	p.player.Store(&sfzRegionPlayer{player, region})
+}
+
+func (p *sfzPlayer) release() {
+	if player := p.player.Load(); player != nil && !player.region.OneShot {
+		player.release()
+	}
+}
+
+// isOneShot returns true if the region of the current note is one-shot. A note without a region has nothing to release.
+func (p *sfzPlayer) isOneShot() bool {
+	player := p.player.Load()
+	return player == nil || player.region.OneShot
+}
+
+func (p *sfzPlayer) setSmoothing(sampleRate beep.SampleRate, smoothingTime time.Duration) {
+	p.smoothing.Store(int64(smoothingTime))
+	if player := p.player.Load(); player != nil {
+		player.setSmoothing(sampleRate, smoothingTime)
+	}
+}
@@ -0,0 +1,254 @@
+package streamers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/gopxl/beep/v2/wav"
+
+	"github.com/HuBeZa/synth/streamers/frequencies"
+)
+
+func TestParseSfzNote(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "60", want: 60},
+		{value: "0", want: 0},
+		{value: "c4", want: 60},
+		{value: "C#4", want: 61},
+		{value: "db4", want: 61},
+		{value: "a4", want: 69},
+		{value: "c-1", want: 0},
+		{value: "g9", want: 127},
+		{value: "g#9", wantErr: true},
+		{value: "b#9", wantErr: true},
+		{value: "c#-1", want: 1},
+		{value: "cb-1", wantErr: true},
+		{value: "a4+50c", wantErr: true},
+		{value: "a4+0c", want: 69},
+		{value: "440Hz", want: 69},
+		{value: "h4", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseSfzNote(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseSfzNote(%q) = %v, want an error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseSfzNote(%q) returned error %v", tt.value, err)
+		} else if got != tt.want {
+			t.Errorf("parseSfzNote(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestStripSfzComments(t *testing.T) {
+	tests := []struct {
+		name          string
+		line          string
+		inComment     bool
+		want          string
+		wantInComment bool
+	}{
+		{name: "no comment", line: "<region> key=60", want: "<region> key=60"},
+		{name: "line comment", line: "key=60 // middle C", want: "key=60 "},
+		{name: "block comment", line: "key=60 /* middle C */ volume=-3", want: "key=60  volume=-3"},
+		{name: "open block comment", line: "key=60 /* middle", want: "key=60 ", wantInComment: true},
+		{name: "within block comment", line: "C", inComment: true, want: "", wantInComment: true},
+		{name: "close block comment", line: "C */ volume=-3", inComment: true, want: " volume=-3"},
+		{name: "line comment in block comment", line: "// */ key=60", inComment: true, want: " key=60"},
+		{name: "block comment in line comment", line: "key=60 // /* C", want: "key=60 "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotInComment := stripSfzComments(tt.line, tt.inComment)
+			if got != tt.want || gotInComment != tt.wantInComment {
+				t.Errorf("stripSfzComments(%q, %v) = (%q, %v), want (%q, %v)",
+					tt.line, tt.inComment, got, gotInComment, tt.want, tt.wantInComment)
+			}
+		})
+	}
+}
+
+func TestSplitSfzHeaders(t *testing.T) {
+	tests := []struct {
+		line string
+		want []sfzLinePart
+	}{
+		{line: "key=60", want: []sfzLinePart{{opcodes: "key=60"}}},
+		{line: "<region>", want: []sfzLinePart{{}, {header: "region"}}},
+		{line: "<region> key=60", want: []sfzLinePart{{}, {header: "region", opcodes: " key=60"}}},
+		{
+			line: "volume=-3 <group> lovel=1 <region>key=60",
+			want: []sfzLinePart{{opcodes: "volume=-3 "}, {header: "group", opcodes: " lovel=1 "}, {header: "region", opcodes: "key=60"}},
+		},
+	}
+	for _, tt := range tests {
+		if got := splitSfzHeaders(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitSfzHeaders(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseSfzOpcodes(t *testing.T) {
+	tests := []struct {
+		text string
+		want map[string]string
+	}{
+		{text: "", want: map[string]string{}},
+		{text: " key=60", want: map[string]string{"key": "60"}},
+		{text: "key=60 volume=-3.5", want: map[string]string{"key": "60", "volume": "-3.5"}},
+		{text: "sample=Grand Piano/C4 soft.wav key=c4", want: map[string]string{"sample": "Grand Piano/C4 soft.wav", "key": "c4"}},
+		{text: "key=60 key=61", want: map[string]string{"key": "61"}},
+	}
+	for _, tt := range tests {
+		if got := parseSfzOpcodes(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSfzOpcodes(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLoadSfzInstrument(t *testing.T) {
+	dir := t.TempDir()
+	writeTestSample(t, filepath.Join(dir, "samples", "low.wav"))
+	writeTestSample(t, filepath.Join(dir, "samples", "high note.wav"))
+	sfzPath := writeTestSfz(t, dir, `// test instrument
+<control> default_path=samples/
+<global> volume=-6
+<group> lovel=1 hivel=63 /* soft
+layer */
+<region> sample=low.wav lokey=c3 hikey=b3 pitch_keycenter=c3
+<region> sample=high note.wav key=c4 tune=-10 loop_mode=one_shot
+<group> lovel=64
+<region> sample=low.wav hikey=59 seq_length=2 seq_position=2 loop_mode=loop_continuous loop_start=10 loop_end=89
+`)
+
+	instrument, err := LoadSfzInstrument(sfzPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	regions := instrument.Regions()
+	if len(regions) != 3 {
+		t.Fatalf("loaded %v regions, want 3", len(regions))
+	}
+	if regions[0].Sample != regions[2].Sample {
+		t.Error("a sample shared by regions should be loaded once")
+	}
+
+	type regionFields struct {
+		LoKey, HiKey, LoVel, HiVel, PitchKeycenter int
+		Tune, Volume                               float64
+		OneShot                                    bool
+		SeqLength, SeqPosition                     int
+		Loop                                       SampleLoop
+	}
+	want := []regionFields{
+		{LoKey: 48, HiKey: 59, LoVel: 1, HiVel: 63, PitchKeycenter: 48, Volume: -6, SeqLength: 1, SeqPosition: 1, Loop: SampleLoop{OneShot, 0, 100}},
+		{LoKey: 60, HiKey: 60, LoVel: 1, HiVel: 63, PitchKeycenter: 60, Tune: -10, Volume: -6, OneShot: true, SeqLength: 1, SeqPosition: 1, Loop: SampleLoop{OneShot, 0, 100}},
+		{LoKey: 0, HiKey: 59, LoVel: 64, HiVel: 127, PitchKeycenter: 60, Volume: -6, SeqLength: 2, SeqPosition: 2, Loop: SampleLoop{Loop, 10, 90}},
+	}
+	for i, r := range regions {
+		got := regionFields{r.LoKey, r.HiKey, r.LoVel, r.HiVel, r.PitchKeycenter, r.Tune, r.Volume, r.OneShot, r.SeqLength, r.SeqPosition, r.Loop}
+		if got != want[i] {
+			t.Errorf("region %v = %+v, want %+v", i+1, got, want[i])
+		}
+	}
+}
+
+func TestLoadSfzInstrumentErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		sfz  string
+	}{
+		{name: "no regions", sfz: "<group> volume=-6\n"},
+		{name: "missing sample", sfz: "<region> key=60\n"},
+		{name: "unknown sample", sfz: "<region> sample=missing.wav\n"},
+		{name: "illegal note", sfz: "<region> sample=test.wav key=h4\n"},
+		{name: "note beyond midi", sfz: "<region> sample=test.wav hikey=g#9\n"},
+		{name: "note with cents", sfz: "<region> sample=test.wav key=a4+50c\n"},
+		{name: "illegal number", sfz: "<region> sample=test.wav volume=loud\n"},
+		{name: "illegal round robin", sfz: "<region> sample=test.wav seq_length=2 seq_position=3\n"},
+		{name: "unknown loop mode", sfz: "<region> sample=test.wav loop_mode=reverse\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestSample(t, filepath.Join(dir, "test.wav"))
+			if _, err := LoadSfzInstrument(writeTestSfz(t, dir, tt.sfz)); err == nil {
+				t.Error("LoadSfzInstrument() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func writeTestSfz(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "test.sfz")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeTestSample writes a 100 samples silent wav file
+func writeTestSample(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	format := beep.Format{SampleRate: 44100, NumChannels: 2, Precision: 2}
+	if err := wav.Encode(file, beep.Take(100, beep.Silence(-1)), format); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSfzOneShotRegions(t *testing.T) {
+	dir := t.TempDir()
+	writeTestSample(t, filepath.Join(dir, "test.wav"))
+	instrument, err := LoadSfzInstrument(writeTestSfz(t, dir, `<region> sample=test.wav key=60 loop_mode=one_shot
+<region> sample=test.wav key=62 loop_mode=loop_sustain
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		note         string
+		wantReleased bool
+	}{
+		{note: "C4", wantReleased: false},
+		{note: "D4", wantReleased: true},
+		// no region, nothing to release
+		{note: "E4", wantReleased: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.note, func(t *testing.T) {
+			freq, _ := frequencies.Parse(tt.note)
+			streamer, err := newDynamicStreamer(44100, freq, 0, 1, NewSfzGenerator(instrument, DefaultSfzVelocity))
+			if err != nil {
+				t.Fatal(err)
+			}
+			streamer.TriggerAttack()
+			streamer.TriggerRelease()
+			if streamer.isReleased != tt.wantReleased {
+				t.Errorf("released = %v, want %v", streamer.isReleased, tt.wantReleased)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package streamers
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+)
+
+const (
+	// DefaultSmoothingTime is the default time constant of smoothed parameters
+	DefaultSmoothingTime = 10 * time.Millisecond
+
+	// the ramp ends when the value is that close to its target
+	smoothingEpsilon = 1e-6
+)
+
+// smoothable is implemented by tone sources with smoothed parameters
+type smoothable interface {
+	setSmoothing(sampleRate beep.SampleRate, smoothingTime time.Duration)
+}
+
+// smoothedParam is a continuous parameter that ramps exponentially towards its target value,
+// to avoid zipper noise when the value jumps.
+// The target may be set by any goroutine, while the current value is advanced by the streaming goroutine only.
+type smoothedParam struct {
+	target  atomic.Uint64
+	coef    atomic.Uint64
+	current float64
+}
+
+// init sets the value without ramping. It should be called before streaming starts.
+func (p *smoothedParam) init(value float64, sampleRate beep.SampleRate, smoothingTime time.Duration) {
+	storeFloat(&p.target, value)
+	p.current = value
+	p.setSmoothing(sampleRate, smoothingTime)
+}
+
+func (p *smoothedParam) Target() float64 {
+	return loadFloat(&p.target)
+}
+
+func (p *smoothedParam) SetTarget(value float64) {
+	storeFloat(&p.target, value)
+}
+
+// setSmoothing sets the time constant of the ramp - the time it takes to cover ~63% of the distance to the target.
+func (p *smoothedParam) setSmoothing(sampleRate beep.SampleRate, smoothingTime time.Duration) {
+	coef := 1.0
+	if n := sampleRate.N(smoothingTime); n > 0 {
+		coef = 1 - math.Exp(-1/float64(n))
+	}
+	storeFloat(&p.coef, coef)
+}
+
+// next advances the ramp by a single sample, and returns the current value
+func (p *smoothedParam) next() float64 {
+	target := p.Target()
+	if p.current != target {
+		p.current += (target - p.current) * loadFloat(&p.coef)
+		if math.Abs(target-p.current) < smoothingEpsilon {
+			p.current = target
+		}
+	}
+	return p.current
+}
+
+func validateSmoothing(smoothingTime time.Duration) error {
+	if smoothingTime < 0 {
+		return fmt.Errorf("smoothing time should not be negative")
+	}
+	return nil
+}
@@ -3,8 +3,6 @@ package streamers
 import (
 	"fmt"
 	"strconv"
-
-	"github.com/gopxl/beep/v2/generators"
 )
 
 type Waveform int
@@ -16,6 +14,14 @@ const (
 	Square
 	Sawtooth
 	ReversedSawtooth
+	FM
+	Pulse
+	ExpSquare
+	LogSquare
+	White
+	Pink
+	Brown
+	Wavetable
 )
 
 var (
@@ -23,21 +29,47 @@ var (
 		Sine:             "sine",
 		Triangle:         "triangle",
 		Square:           "square",
+		Pulse:            "pulse",
+		ExpSquare:        "exp square",
+		LogSquare:        "log square",
 		Sawtooth:         "sawtooth",
 		ReversedSawtooth: "reversed sawtooth",
+		FM:               "fm",
+		Wavetable:        "wavetable",
+		White:            "white noise",
+		Pink:             "pink noise",
+		Brown:            "brown noise",
 	}
 
 	waveformsToGenerator = map[Waveform]StreamerGeneratorFunc{
-		Sine:             generators.SineTone,
-		Triangle:         generators.TriangleTone,
-		Square:           generators.SquareTone,
-		Sawtooth:         generators.SawtoothTone,
-		ReversedSawtooth: generators.SawtoothToneReversed,
+		Sine:             newOscillatorGenerator(sineShape),
+		Triangle:         newOscillatorGenerator(triangleShape),
+		Square:           newOscillatorGenerator(squareShape),
+		Pulse:            NewPulseGenerator(DefaultPulseWidth(), false),
+		ExpSquare:        NewExpSquareGenerator(DefaultCurvature, false),
+		LogSquare:        NewLogSquareGenerator(DefaultCurvature, false),
+		Sawtooth:         newOscillatorGenerator(sawtoothShape),
+		ReversedSawtooth: newOscillatorGenerator(reversedSawtoothShape),
+		FM:               NewFMGenerator(DefaultFMPatch()),
+		Wavetable:        NewWavetableGenerator(BuiltinWavetableBank(), DefaultWavetablePosition()),
+		White:            NewNoiseGenerator(WhiteNoise, DefaultNoiseSeed),
+		Pink:             NewNoiseGenerator(PinkNoise, DefaultNoiseSeed),
+		Brown:            NewNoiseGenerator(BrownNoise, DefaultNoiseSeed),
+	}
+
+	// waveforms that have a band-limited (anti-aliased) version. The rest are band-limited by nature.
+	bandLimitedWaveformsToGenerator = map[Waveform]StreamerGeneratorFunc{
+		Square:           newOscillatorGenerator(bandLimitedSquareShape),
+		Pulse:            NewPulseGenerator(DefaultPulseWidth(), true),
+		ExpSquare:        NewExpSquareGenerator(DefaultCurvature, true),
+		LogSquare:        NewLogSquareGenerator(DefaultCurvature, true),
+		Sawtooth:         newOscillatorGenerator(bandLimitedSawtoothShape),
+		ReversedSawtooth: newOscillatorGenerator(bandLimitedReversedSawtoothShape),
 	}
 )
 
 func AllWaveforms() []Waveform {
-	return []Waveform{Sine, Triangle, Square, Sawtooth, ReversedSawtooth}
+	return []Waveform{Sine, Triangle, Square, Pulse, ExpSquare, LogSquare, Sawtooth, ReversedSawtooth, FM, Wavetable, White, Pink, Brown}
 }
 
 func (w Waveform) Equals(other Waveform) bool {
@@ -51,7 +83,22 @@ func (w Waveform) String() string {
 	return strconv.Itoa(int(w))
 }
 
-func (w Waveform) streamerGenerator() (StreamerGeneratorFunc, error) {
+func (w Waveform) hasBandLimited() bool {
+	_, ok := bandLimitedWaveformsToGenerator[w]
+	return ok
+}
+
+func (w Waveform) isNoise() bool {
+	return w == White || w == Pink || w == Brown
+}
+
+func (w Waveform) streamerGenerator(bandLimited bool) (StreamerGeneratorFunc, error) {
+	if bandLimited {
+		if generator, ok := bandLimitedWaveformsToGenerator[w]; ok {
+			return generator, nil
+		}
+	}
+
 	generator, ok := waveformsToGenerator[w]
 	if !ok {
 		return nil, fmt.Errorf("waveform unknown")
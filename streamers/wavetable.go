@@ -0,0 +1,281 @@
+package streamers
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/gopxl/beep/v2/wav"
+)
+
+const (
+	// DefaultWavetableFrameSize is the number of samples in a single cycle frame of a wavetable file
+	DefaultWavetableFrameSize = 2048
+
+	builtinWavetableName = "built-in"
+)
+
+var builtinWavetableBank = newBuiltinWavetableBank()
+
+// WavetableBank is a list of single cycle waveforms (frames) with the same length.
+// A wavetable oscillator plays a single cycle per period, and crossfades between adjacent frames by its position.
+type WavetableBank struct {
+	name   string
+	frames [][]float64
+}
+
+func NewWavetableBank(name string, frames [][]float64) (*WavetableBank, error) {
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("wavetable bank should have at least 1 frame")
+	}
+	for _, frame := range frames {
+		if len(frame) < 2 || len(frame) != len(frames[0]) {
+			return nil, fmt.Errorf("wavetable frames should have the same length, of at least 2 samples")
+		}
+	}
+	return &WavetableBank{name, frames}, nil
+}
+
+// BuiltinWavetableBank returns a wavetable bank generated from the basic waveforms: sine, triangle, square & sawtooth
+func BuiltinWavetableBank() *WavetableBank {
+	return builtinWavetableBank
+}
+
+func newBuiltinWavetableBank() *WavetableBank {
+	shapes := []waveShape{sineShape, triangleShape, squareShape, sawtoothShape}
+	frames := make([][]float64, len(shapes))
+	for i, shape := range shapes {
+		frames[i] = make([]float64, DefaultWavetableFrameSize)
+		for j := range frames[i] {
+			frames[i][j] = shape(float64(j)/DefaultWavetableFrameSize, 0)
+		}
+	}
+
+	bank, _ := NewWavetableBank(builtinWavetableName, frames)
+	return bank
+}
+
+// LoadWavetableBank reads a WAV file, and splits it into frames of frameSize samples.
+// A file shorter than frameSize is a single frame. Stereo files are mixed to mono.
+func LoadWavetableBank(path string, frameSize int) (*WavetableBank, error) {
+	if frameSize < 2 {
+		return nil, fmt.Errorf("frame size should be at least 2 samples")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	streamer, _, err := wav.Decode(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	defer streamer.Close()
+
+	samples := make([]float64, 0, streamer.Len())
+	buf := make([][2]float64, 512)
+	for {
+		n, ok := streamer.Stream(buf)
+		for _, sample := range buf[:n] {
+			samples = append(samples, (sample[0]+sample[1])/2)
+		}
+		if !ok {
+			break
+		}
+	}
+	if err := streamer.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(samples) < frameSize {
+		frameSize = len(samples)
+	}
+	frames := make([][]float64, 0, len(samples)/max(frameSize, 1))
+	for i := 0; i+frameSize <= len(samples) && frameSize > 0; i += frameSize {
+		frames = append(frames, samples[i:i+frameSize])
+	}
+
+	return NewWavetableBank(filepath.Base(path), frames)
+}
+
+func (b *WavetableBank) Name() string {
+	return b.name
+}
+
+func (b *WavetableBank) Frames() int {
+	return len(b.frames)
+}
+
+// Frame returns the crossfaded frame at position (0 - first frame, 1 - last frame), resampled to size samples
+func (b *WavetableBank) Frame(position float64, size int) []float64 {
+	frame := make([]float64, size)
+	for i := range frame {
+		frame[i] = b.sample(position, float64(i)/float64(len(frame)))
+	}
+	return frame
+}
+
+// sample interpolates linearly between the samples of a frame, and between adjacent frames
+func (b *WavetableBank) sample(position, phase float64) float64 {
+	framePos := position * float64(len(b.frames)-1)
+	frameIdx := int(framePos)
+	if frameIdx >= len(b.frames)-1 {
+		return b.frameSample(b.frames[len(b.frames)-1], phase)
+	}
+
+	fade := framePos - float64(frameIdx)
+	v := b.frameSample(b.frames[frameIdx], phase)
+	if fade == 0 {
+		return v
+	}
+	return v + fade*(b.frameSample(b.frames[frameIdx+1], phase)-v)
+}
+
+func (b *WavetableBank) frameSample(frame []float64, phase float64) float64 {
+	pos := phase * float64(len(frame))
+	i := int(pos)
+	frac := pos - float64(i)
+	curr, next := frame[i%len(frame)], frame[(i+1)%len(frame)]
+	return curr + frac*(next-curr)
+}
+
+// WavetablePosition sets the position of the wavetable oscillator, and its modulation
+type WavetablePosition struct {
+	// 0 (first frame) to 1 (last frame)
+	Position float64
+	// LFO frequency in Hz. 0 turns the LFO off
+	LFORate float64
+	// LFO amplitude, 0 to 1, added to & subtracted from the position
+	LFODepth float64
+	// envelope amount, -1 to 1. On every attack, the envelope moves the position from 0 to amount within EnvelopeTime
+	EnvelopeAmount float64
+	EnvelopeTime   time.Duration
+}
+
+func DefaultWavetablePosition() WavetablePosition {
+	return WavetablePosition{}
+}
+
+func (p WavetablePosition) validate() error {
+	if p.Position < 0 || p.Position > 1 {
+		return fmt.Errorf("wavetable position should be between 0 to 1")
+	}
+	if p.LFORate < 0 {
+		return fmt.Errorf("wavetable LFO rate should not be negative")
+	}
+	if p.LFODepth < 0 || p.LFODepth > 1 {
+		return fmt.Errorf("wavetable LFO depth should be between 0 to 1")
+	}
+	if p.EnvelopeAmount < -1 || p.EnvelopeAmount > 1 {
+		return fmt.Errorf("wavetable envelope amount should be between -1 to 1")
+	}
+	if p.EnvelopeTime < 0 {
+		return fmt.Errorf("wavetable envelope time should not be negative")
+	}
+	return nil
+}
+
+// wavetablePositionSetter is implemented by tone sources with a wavetable position, that can be changed while streaming
+type wavetablePositionSetter interface {
+	setWavetablePosition(position WavetablePosition)
+}
+
+// NewWavetableGenerator returns a generator of wavetable oscillators, to be used with DynamicStreamer.SetGenerator
+func NewWavetableGenerator(bank *WavetableBank, position WavetablePosition) StreamerGeneratorFunc {
+	return func(sampleRate beep.SampleRate, freq float64) (beep.Streamer, error) {
+		return newWavetableOscillator(sampleRate, freq, bank, position)
+	}
+}
+
+type wavetableOscillator struct {
+	*oscillator
+	bank      *WavetableBank
+	position  smoothedParam
+	lfoRate   atomic.Uint64
+	lfoDepth  atomic.Uint64
+	lfoPhase  float64
+	envAmount atomic.Uint64
+	envLength atomic.Int64
+	envPos    int
+	restart   atomic.Bool
+}
+
+func newWavetableOscillator(sampleRate beep.SampleRate, freq float64, bank *WavetableBank, position WavetablePosition) (*wavetableOscillator, error) {
+	if bank == nil {
+		return nil, fmt.Errorf("wavetable bank is missing")
+	}
+	if err := position.validate(); err != nil {
+		return nil, err
+	}
+
+	o, err := newOscillator(sampleRate, freq, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &wavetableOscillator{
+		oscillator: o,
+		bank:       bank,
+	}
+	w.position.init(position.Position, sampleRate, DefaultSmoothingTime)
+	w.setWavetablePosition(position)
+	return w, nil
+}
+
+func (w *wavetableOscillator) Stream(samples [][2]float64) (n int, ok bool) {
+	if w.restart.CompareAndSwap(true, false) {
+		w.envPos = 0
+	}
+
+	sampleRate := float64(w.sampleRate)
+	lfoIncrement := loadFloat(&w.lfoRate) / sampleRate
+	lfoDepth := loadFloat(&w.lfoDepth)
+	envAmount := loadFloat(&w.envAmount)
+	envLength := int(w.envLength.Load())
+
+	for i := range samples {
+		position := w.position.next()
+		if lfoDepth > 0 {
+			position += lfoDepth * math.Sin(2*math.Pi*w.lfoPhase)
+			_, w.lfoPhase = math.Modf(w.lfoPhase + lfoIncrement)
+		}
+		if envAmount != 0 {
+			progress := 1.0
+			if w.envPos < envLength {
+				progress = float64(w.envPos) / float64(envLength)
+				w.envPos++
+			}
+			position += envAmount * progress
+		}
+		position = min(max(position, 0), 1)
+
+		v := w.amplitude.next() * w.bank.sample(position, w.phase)
+		samples[i][0] = v
+		samples[i][1] = v
+		_, w.phase = math.Modf(w.phase + w.frequency.next()/sampleRate)
+	}
+	return len(samples), true
+}
+
+func (w *wavetableOscillator) setWavetablePosition(position WavetablePosition) {
+	w.position.SetTarget(position.Position)
+	storeFloat(&w.lfoRate, position.LFORate)
+	storeFloat(&w.lfoDepth, position.LFODepth)
+	storeFloat(&w.envAmount, position.EnvelopeAmount)
+	w.envLength.Store(int64(w.sampleRate.N(position.EnvelopeTime)))
+}
+
+func (w *wavetableOscillator) setSmoothing(sampleRate beep.SampleRate, smoothingTime time.Duration) {
+	w.oscillator.setSmoothing(sampleRate, smoothingTime)
+	w.position.setSmoothing(sampleRate, smoothingTime)
+}
+
+func (w *wavetableOscillator) retrigger() {
+	w.restart.Store(true)
+}